@@ -95,14 +95,14 @@ func (b *BigIP) CreateWebtop(ctx context.Context, webtop Webtop) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
-	return b.post(webtop, uriMgmt, uriTm, uriApm, uriResource, uriWebtop)
+	return b.postContext(ctx, webtop, uriMgmt, uriTm, uriApm, uriResource, uriWebtop)
 }
 
 func (b *BigIP) DeleteWebtop(ctx context.Context, name string) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
-	return b.delete(uriMgmt, uriTm, uriApm, uriResource, uriWebtop, name)
+	return b.deleteContext(ctx, uriMgmt, uriTm, uriApm, uriResource, uriWebtop, name)
 }
 
 func (b *BigIP) GetWebtop(ctx context.Context, name string) (*WebtopRead, error) {
@@ -110,7 +110,7 @@ func (b *BigIP) GetWebtop(ctx context.Context, name string) (*WebtopRead, error)
 		return nil, ctx.Err()
 	}
 	var webtop WebtopRead
-	err, _ := b.getForEntity(&webtop, uriMgmt, uriTm, uriApm, uriResource, uriWebtop, name)
+	err, _ := b.getForEntityContext(ctx, &webtop, uriMgmt, uriTm, uriApm, uriResource, uriWebtop, name)
 	return &webtop, err
 }
 
@@ -118,7 +118,7 @@ func (b *BigIP) ModifyWebtop(ctx context.Context, name string, webtop WebtopConf
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
-	return b.patch(webtop, uriMgmt, uriTm, uriApm, uriResource, uriWebtop, name)
+	return b.patchContext(ctx, webtop, uriMgmt, uriTm, uriApm, uriResource, uriWebtop, name)
 }
 
 // AccessProfiles contains a list of all access profiles on the BIG-IP system.
@@ -15,10 +15,6 @@ To read more about As3 check https://clouddocs.f5.com/products/extensions/f5-app
 */
 package bigip
 
-import (
-	"log"
-)
-
 type Appsvcs struct {
 	Appsvcs []Appsvc01 `json:"items"`
 }
@@ -116,7 +112,6 @@ const (
 func (b *BigIP) Appsvc01() (*Appsvc01, error) {
 	var appsvc01 Appsvc01
 	err, _ := b.getForEntity(uriSam01, uriSha, uriAppsvcs, uriDecl)
-	log.Printf("i am here in sdk %+v  ", appsvc01)
 	if err != nil {
 		return nil, err
 	}
@@ -126,7 +121,6 @@ func (b *BigIP) Appsvc01() (*Appsvc01, error) {
 func (b *BigIP) Appsvc02() (*Appsvc02, error) {
 	var appsvc02 Appsvc02
 	err, _ := b.getForEntity(uriSam02, uriSha, uriAppsvcs, uriDecl)
-	log.Printf("i am here in sdk %+v  ", appsvc02)
 	if err != nil {
 		return nil, err
 	}
@@ -136,18 +130,18 @@ func (b *BigIP) Appsvc02() (*Appsvc02, error) {
 
 // CreateAppsvcs creates a new iAppsvcs on the system.
 func (b *BigIP) CreateAppsvc01(p *Appsvc01) error {
-	log.Printf("++++++ Here is what terraform is sending to bigip ................ : %+v ", p)
+	b.log().Debug("sending appsvcs declaration", "declaration", p)
 	err := b.post(p, uriMgmt, uriSha, uriAppsvcs, uriDecl)
 	if err != nil {
-		log.Println(" API call not successfull  ", err)
+		b.log().Error("appsvcs declaration failed", "error", err)
 	}
 	return nil
 }
 func (b *BigIP) CreateAppsvc02(p *Appsvc02) error {
-	log.Printf("++++++ Here is what terraform is sending to bigip ................ : %+v ", p)
+	b.log().Debug("sending appsvcs declaration", "declaration", p)
 	err := b.post(p, uriMgmt, uriSha, uriAppsvcs, uriDecl)
 	if err != nil {
-		log.Println(" API call not successfull  ", err)
+		b.log().Error("appsvcs declaration failed", "error", err)
 	}
 	return nil
 }
@@ -159,19 +153,18 @@ func (b *BigIP) DeleteAppsvc02() error {
 }
 
 func (b *BigIP) ModifyAppsvc01(p *Appsvc01) error {
-	log.Printf("++++++ Here is what terraform is sending to bigip ................ : %+v ", p)
+	b.log().Debug("sending appsvcs declaration", "declaration", p)
 	err := b.patch(p, uriMgmt, uriSha, uriAppsvcs, uriDecl)
-	log.Println("value of p in modify +++++++++++++++", p)
 	if err != nil {
-		log.Println(" API call not successfull  ", err)
+		b.log().Error("appsvcs declaration failed", "error", err)
 	}
 	return nil
 }
 func (b *BigIP) ModifyAppsvc02(p *Appsvc02) error {
-	log.Printf("++++++ Here is what terraform is sending to bigip ................ : %+v ", p)
+	b.log().Debug("sending appsvcs declaration", "declaration", p)
 	err := b.patch(p, uriMgmt, uriSha, uriAppsvcs, uriDecl)
 	if err != nil {
-		log.Println(" API call not successfull  ", err)
+		b.log().Error("appsvcs declaration failed", "error", err)
 	}
 	return nil
 }
@@ -13,7 +13,6 @@ package bigip
 import (
 	//"encoding/json"
 	"fmt"
-	"log"
 	"strings"
 )
 
@@ -82,13 +81,11 @@ func (b *BigIP) UpdateIapp(name string, p *Iapp) error {
 	values = append(values, name)
 	// Join three strings into one.
 	result := strings.Join(values, "")
-	fmt.Println(result)
 	return b.patch(p, uriSysa, uriApp, uriService, result)
 }
 
 func (b *BigIP) Iapp(name, partition string) (*Iapp, error) {
 	var iapp Iapp
-	log.Println(" Value of iapp before read  ", &iapp)
 	values := []string{}
 	values = append(values, fmt.Sprintf("~%s~", partition))
 	values = append(values, name)
@@ -97,11 +94,9 @@ func (b *BigIP) Iapp(name, partition string) (*Iapp, error) {
 	// Join three strings into one.
 	result := strings.Join(values, "")
 	err, _ := b.getForEntity(&iapp, uriSysa, uriApp, uriService, result)
-	log.Println(" I am here in sdk with  ", err)
 	if err != nil {
 		return nil, err
 	}
-	log.Println(" Value of iapp after reading  ", &iapp)
 	return &iapp, nil
 }
 
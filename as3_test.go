@@ -0,0 +1,649 @@
+package bigip
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type As3TestSuite struct {
+	suite.Suite
+	Client       *BigIP
+	Server       *httptest.Server
+	Requests     []string
+	ResponseFunc func(http.ResponseWriter, *http.Request)
+	mu           sync.Mutex
+}
+
+func (s *As3TestSuite) SetupSuite() {
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		s.mu.Lock()
+		s.Requests = append(s.Requests, strings.TrimSpace(r.Method+" "+r.URL.RequestURI()+" "+string(body)))
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if s.ResponseFunc != nil {
+			s.ResponseFunc(w, r)
+		}
+	}))
+	s.Client = NewSession(&Config{
+		Address:           s.Server.URL,
+		CertVerifyDisable: true,
+		ConfigOptions: &ConfigOptions{
+			APICallTimeout: 5 * time.Second,
+			RetryPolicy:    &ExponentialBackoff{MaxAttempts: 2, BaseDelay: time.Millisecond},
+		},
+	})
+	s.Client.UserAgent = "go-bigip-test"
+	shortenPollInterval(s.T(), &as3PollInterval)
+}
+
+func (s *As3TestSuite) TearDownSuite() {
+	s.Server.Close()
+}
+
+func (s *As3TestSuite) SetupTest() {
+	s.ResponseFunc = nil
+	s.Requests = nil
+}
+
+func TestAs3Suite(t *testing.T) {
+	suite.Run(t, new(As3TestSuite))
+}
+
+const as3Declaration = `{
+	"class": "AS3",
+	"action": "deploy",
+	"persist": false,
+	"historyLimit": 2,
+	"declaration": {
+		"class": "ADC",
+		"schemaVersion": "3.50.0",
+		"id": "example",
+		"label": "",
+		"target": {"address": "10.1.1.4"},
+		"controls": {"class": "Controls", "trace": true, "traceResponse": true},
+		"Example": {
+			"class": "Tenant",
+			"defaultRouteDomain": 0,
+			"web": {
+				"class": "Application",
+				"vs": {
+					"class": "Service_HTTPS",
+					"virtualAddresses": ["10.0.1.10"],
+					"pool": "web_pool",
+					"serverTLS": "webtls",
+					"iRules": ["redirect", {"bigip": "/Common/_sys_https_redirect"}],
+					"profileHTTP": {"use": "http"},
+					"persistenceMethods": []
+				},
+				"web_pool": {
+					"class": "Pool",
+					"monitors": ["http", {"use": "health"}],
+					"members": [{"servicePort": 80, "serverAddresses": ["192.0.2.10", "192.0.2.11"], "shareNodes": false, "ratio": 10}]
+				},
+				"health": {"class": "Monitor", "monitorType": "http", "interval": 5, "send": "GET / HTTP/1.0\r\n\r\n"},
+				"webtls": {"class": "TLS_Server", "certificates": [{"certificate": "webcert"}]},
+				"webcert": {
+					"class": "Certificate",
+					"certificate": {"bigip": "/Common/default.crt"},
+					"privateKey": {"ciphertext": "c2VjcmV0", "protected": "eyJhbGciOiJkaXIifQ"}
+				},
+				"redirect": {"class": "iRule", "iRule": {"base64": "d2hlbiBIVFRQX1JFUVVFU1Qge30="}},
+				"fw": {"class": "Firewall_Rule_List", "rules": [{"name": "allow", "action": "accept"}]}
+			},
+			"api": {"class": "Application", "template": "generic"}
+		},
+		"Other": {"class": "Tenant"}
+	}
+}`
+
+func TestAs3DeclarationRoundTrip(t *testing.T) {
+	req, err := ParseAs3Request(as3Declaration)
+	assert.Nil(t, err)
+
+	decl := req.Declaration
+	assert.Equal(t, []string{"Example", "Other"}, decl.TenantNames())
+	assert.Equal(t, "10.1.1.4", decl.Target.Address)
+	assert.Equal(t, []string{"api", "web"}, decl.Tenants["Example"].ApplicationNames())
+
+	app := decl.Tenants["Example"].Applications["web"]
+	vs := app.Items["vs"].(*As3Service)
+	assert.Equal(t, "Service_HTTPS", vs.As3Class())
+	assert.Equal(t, "web_pool", vs.Pool.Value)
+	assert.Equal(t, "/Common/_sys_https_redirect", vs.IRules[1].BigIP)
+	pool := app.Items["web_pool"].(*As3Pool)
+	assert.Equal(t, "health", pool.Monitors[1].Use)
+	assert.Equal(t, []string{"192.0.2.10", "192.0.2.11"}, pool.Members[0].ServerAddresses)
+	assert.Equal(t, 5, app.Items["health"].(*As3Monitor).Interval)
+	assert.Equal(t, "webcert", app.Items["webtls"].(*As3TLSServer).Certificates[0].Certificate)
+	cert := app.Items["webcert"].(*As3Certificate)
+	assert.Equal(t, "/Common/default.crt", cert.Certificate.BigIP)
+	assert.NotNil(t, cert.PrivateKey.Raw)
+	assert.NotNil(t, app.Items["redirect"].(*As3IRule).IRule.Raw)
+	fw := app.Items["fw"].(*As3Object)
+	assert.Equal(t, "Firewall_Rule_List", fw.As3Class())
+
+	out, err := req.JSON()
+	assert.Nil(t, err)
+	var want, got interface{}
+	assert.Nil(t, json.Unmarshal([]byte(as3Declaration), &want))
+	assert.Nil(t, json.Unmarshal([]byte(out), &got))
+	assert.Equal(t, want, got)
+}
+
+func TestAs3DeclarationCaseSensitiveNames(t *testing.T) {
+	// Tenants and applications named like fields, but for the case.
+	const declaration = `{
+		"class": "AS3",
+		"declaration": {
+			"class": "ADC",
+			"schemaVersion": "3.50.0",
+			"target": {"address": "10.1.1.4"},
+			"Target": {
+				"class": "Tenant",
+				"Remark": {"class": "Application", "template": "generic"},
+				"Label": {"class": "Application", "Class": "kept"}
+			},
+			"Controls": {"class": "Tenant"}
+		}
+	}`
+	req, err := ParseAs3Request(declaration)
+	if !assert.Nil(t, err) {
+		return
+	}
+	decl := req.Declaration
+	assert.Equal(t, []string{"Controls", "Target"}, decl.TenantNames())
+	assert.Nil(t, decl.Controls)
+	assert.Equal(t, "10.1.1.4", decl.Target.Address)
+	assert.Equal(t, []string{"Label", "Remark"}, decl.Tenants["Target"].ApplicationNames())
+	assert.Equal(t, "", decl.Tenants["Target"].Remark)
+	assert.Equal(t, json.RawMessage(`"kept"`), decl.Tenants["Target"].Applications["Label"].Extra["Class"])
+
+	out, err := req.JSON()
+	assert.Nil(t, err)
+	assert.JSONEq(t, declaration, out)
+
+	b := &BigIP{}
+	tenants, count, apps := b.GetTenantList(declaration)
+	assert.Equal(t, "Controls,Target", tenants)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, "Label,Remark", apps)
+	assert.Equal(t, "10.1.1.4", b.GetTarget(declaration))
+}
+
+func TestAs3DeclarationBuilder(t *testing.T) {
+	req := NewAs3Request("3.50.0")
+	req.Declaration.Tenant("Prod").Application("web").
+		Add("vs", &As3Service{Class: "Service_HTTP", VirtualAddresses: []interface{}{"10.0.1.10"}, Pool: &As3Pointer{Value: "web_pool"}}).
+		Add("web_pool", &As3Pool{Monitors: []As3Pointer{{Value: "http"}}, Members: []As3PoolMember{{ServicePort: 80, ServerAddresses: []string{"192.0.2.10"}}}})
+
+	out, err := req.JSON()
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"class": "AS3", "action": "deploy", "persist": true,
+		"declaration": {
+			"class": "ADC", "schemaVersion": "3.50.0",
+			"Prod": {"class": "Tenant", "web": {"class": "Application",
+				"vs": {"class": "Service_HTTP", "virtualAddresses": ["10.0.1.10"], "pool": "web_pool"},
+				"web_pool": {"class": "Pool", "monitors": ["http"], "members": [{"servicePort": 80, "serverAddresses": ["192.0.2.10"]}]}
+			}}
+		}
+	}`, out)
+}
+
+func TestAs3StringFunctions(t *testing.T) {
+	b := &BigIP{}
+	tenants, count, apps := b.GetTenantList(as3Declaration)
+	assert.Equal(t, "Example,Other", tenants)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, "api,web", apps)
+	assert.Equal(t, "10.1.1.4", b.GetTarget(as3Declaration))
+	assert.Equal(t, "app1,app2", b.GetAppsList(`{"schemaVersion": "3.50.0", "app2": {"class": "Application"}, "app1": {"class": "Application"}}`))
+
+	// Unexpected shapes are not a panic.
+	for _, body := range []interface{}{`{"declaration": {"target": "10.1.1.4", "Example": {"class": "Tenant"}}}`, `{"declaration": []}`, `[]`, `not json`, 42} {
+		assert.NotPanics(t, func() {
+			b.GetTenantList(body)
+			b.GetTarget(body)
+			b.GetAppsList(body)
+		})
+	}
+}
+
+func (s *As3TestSuite) TestAddTeemAgent() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version":"3.50.0","release":"5","schemaCurrent":"3.50.0","schemaMinimum":"3.0.0"}`))
+	}
+
+	out, err := s.Client.AddTeemAgent(as3Declaration)
+	assert.Nil(s.T(), err)
+	req, err := ParseAs3Request(out)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "go-bigip-test", req.Declaration.Controls.UserAgent)
+	assert.Equal(s.T(), true, *req.Declaration.Controls.Trace)
+	assert.Equal(s.T(), json.RawMessage("true"), req.Declaration.Controls.Extra["traceResponse"])
+
+	_, err = s.Client.AddTeemAgent(`not json`)
+	assert.NotNil(s.T(), err)
+}
+
+func (s *As3TestSuite) TestGetAs3() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"class":"ADC","schemaVersion":"3.50.0","id":"autogen","updateMode":"selective","controls":{"class":"Controls","archiveTimestamp":"2024-05-01T10:00:00Z"},"Example":{"class":"Tenant","web":{"class":"Application","pool":{"class":"Pool"}}}}`))
+	}
+
+	out, err := s.Client.GetAs3("Example", "web", false)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), []string{"GET /mgmt/shared/appsvcs/declare/Example"}, s.Requests)
+	assert.JSONEq(s.T(), `{"class":"AS3","action":"deploy","persist":true,"declaration":{"class":"ADC","schemaVersion":"3.50.0","id":"autogen","Example":{"class":"Tenant","web":{"class":"Application","pool":{"class":"Pool"}}}}}`, out)
+}
+
+// as3Tasks answers AS3 requests by starting the tasks in order, and task
+// polls with "in progress" once, then with the results of the task.
+func (s *As3TestSuite) as3Tasks(results ...string) {
+	started, polled := 0, map[string]bool{}
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/mgmt/shared/appsvcs/task":
+			w.Write([]byte(`{"items":[{"id":"t0","results":[{"message":"success","code":200}]}]}`))
+		case strings.HasPrefix(r.URL.Path, "/mgmt/shared/appsvcs/task/"):
+			id := strings.TrimPrefix(r.URL.Path, "/mgmt/shared/appsvcs/task/")
+			if !polled[id] {
+				polled[id] = true
+				w.Write([]byte(`{"id":"` + id + `","results":[{"message":"in progress","code":0}]}`))
+				return
+			}
+			n := int(id[1] - '0')
+			w.Write([]byte(`{"id":"` + id + `","results":` + results[n-1] + `}`))
+		default:
+			started++
+			fmt.Fprintf(w, `{"id":"t%d","results":[{"message":"Declaration successfully submitted","code":0}]}`, started)
+		}
+	}
+}
+
+func (s *As3TestSuite) TestPostAs3Bigip() {
+	s.as3Tasks(`[{"code":200,"message":"success","tenant":"Example","runTime":1500},{"code":200,"message":"no change","tenant":"Other"}]`)
+
+	err, tenants, id := s.Client.PostAs3Bigip(as3Declaration, "Example,Other", "")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "Example,Other", tenants)
+	assert.Equal(s.T(), "t1", id)
+	assert.True(s.T(), strings.HasPrefix(s.Requests[0], "POST /mgmt/shared/appsvcs/declare/Example,Other?async=true "))
+	assert.Equal(s.T(), []string{"GET /mgmt/shared/appsvcs/task/t1", "GET /mgmt/shared/appsvcs/task/t1"}, s.Requests[1:])
+
+	s.as3Tasks(`[{"code":200,"message":"success","tenant":"Example","runTime":1500}]`)
+	result, err := s.Client.PostAs3Result(context.Background(), as3Declaration, "Example", "")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), &As3Result{TaskID: "t1", Tenants: []As3TenantResult{{Tenant: "Example", Code: 200, Message: "success", RunTime: 1500}}}, result)
+}
+
+func (s *As3TestSuite) TestPostAs3BigipPartialSuccess() {
+	s.as3Tasks(`[{"code":200,"message":"success","tenant":"Example"},{"code":422,"message":"declaration failed","response":"01020036:3: The requested pool (/Other/web/pool) was not found.","tenant":"Other"}]`)
+
+	err, tenants, id := s.Client.PostAs3Bigip(as3Declaration, "", "")
+	var partial *As3PartialSuccessError
+	assert.True(s.T(), errors.As(err, &partial))
+	assert.Equal(s.T(), "Example", tenants)
+	assert.Equal(s.T(), "t1", id)
+	assert.Equal(s.T(), []string{"Other"}, partial.Result.Failed())
+	assert.Contains(s.T(), err.Error(), "Other: 422 declaration failed: 01020036:3: The requested pool")
+}
+
+func (s *As3TestSuite) TestPostAs3BigipBusy() {
+	s.as3Tasks(`[{"code":503,"message":"Configuration operation in progress on device, please try again in 2 minutes"}]`,
+		`[{"code":200,"message":"success","tenant":"Example"}]`)
+
+	err, tenants, id := s.Client.PostAs3Bigip(as3Declaration, "Example", "")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "Example", tenants)
+	assert.Equal(s.T(), "t2", id)
+	var methods []string
+	for _, r := range s.Requests {
+		methods = append(methods, strings.SplitN(r, " ", 2)[0])
+	}
+	assert.Equal(s.T(), []string{"POST", "GET", "GET", "GET", "POST", "GET", "GET"}, methods)
+}
+
+func (s *As3TestSuite) TestModifyAs3InvalidDeclaration() {
+	s.as3Tasks(`[{"code":422,"message":"declaration is invalid","errors":["/Example/web/vs: should have required property 'virtualAddresses'"]}]`)
+
+	err := s.Client.ModifyAs3("Example", as3Declaration)
+	var as3Err *As3Error
+	assert.True(s.T(), errors.As(err, &as3Err))
+	assert.Equal(s.T(), "AS3 task t1 failed: 422 declaration is invalid (/Example/web/vs: should have required property 'virtualAddresses')", err.Error())
+	assert.Equal(s.T(), "PATCH", strings.SplitN(s.Requests[0], " ", 2)[0])
+}
+
+func (s *As3TestSuite) TestDeleteAs3Bigip() {
+	s.as3Tasks(`[{"code":200,"message":"success","tenant":"Example"},{"code":422,"message":"declaration failed","tenant":"Other"}]`)
+
+	err, failed := s.Client.DeleteAs3Bigip("Example,Other")
+	var partial *As3PartialSuccessError
+	assert.True(s.T(), errors.As(err, &partial))
+	assert.Equal(s.T(), "Other", failed)
+	assert.Equal(s.T(), "DELETE /mgmt/shared/appsvcs/declare/Example,Other?async=true", s.Requests[0])
+}
+
+func (s *As3TestSuite) TestPostPerAppBigIp() {
+	s.as3Tasks(`[{"code":200,"message":"success","tenant":"Example"}]`)
+
+	err, id := s.Client.PostPerAppBigIp(`{"schemaVersion":"3.50.0","web":{"class":"Application"}}`, "Example", "")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "t1", id)
+	assert.Equal(s.T(), `POST /mgmt/shared/appsvcs/declare/Example/applications/?async=true {"schemaVersion":"3.50.0","web":{"class":"Application"}}`, s.Requests[0])
+}
+
+func (s *As3TestSuite) TestAs3TaskErrorBody() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":200,"message":"unexpected"}`))
+	}
+
+	err, _, _ := s.Client.PostAs3Bigip(as3Declaration, "Example", "")
+	assert.NotNil(s.T(), err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.as3Tasks()
+	_, err = s.Client.WaitForAs3Task(ctx, "t1")
+	assert.True(s.T(), errors.Is(err, context.Canceled))
+}
+
+func (s *As3TestSuite) TestWaitForAs3TaskErrors() {
+	var polls int
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/t1") && polls == 1:
+			// AS3 restarting.
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"code":500,"message":"restarting"}`))
+		case strings.HasSuffix(r.URL.Path, "/t1"):
+			w.Write([]byte(`{"id":"t1","results":[{"code":200,"message":"success","tenant":"Example"}]}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":400,"message":"invalid task id"}`))
+		}
+	}
+
+	_, err := s.Client.WaitForAs3Task(context.Background(), "t1")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, polls)
+
+	polls = 0
+	_, err = s.Client.WaitForAs3Task(context.Background(), "bad")
+	assert.True(s.T(), IsValidationError(err), "unexpected error: %v", err)
+	assert.Equal(s.T(), 1, polls)
+}
+
+func (s *As3TestSuite) TestPlanAs3() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST":
+			w.Write([]byte(`{"id":"t1","results":[{"message":"Declaration successfully submitted","code":0}]}`))
+		case strings.HasPrefix(r.URL.Path, "/mgmt/shared/appsvcs/task/"):
+			w.Write([]byte(`{"id":"t1","results":[{"code":200,"message":"success","tenant":"Example","dryRun":true},{"code":200,"message":"success","tenant":"New","dryRun":true}]}`))
+		default:
+			w.Write([]byte(`{"class":"ADC","schemaVersion":"3.45.0","id":"autogen_1","updateMode":"selective","controls":{"class":"Controls","archiveTimestamp":"2024-05-01T10:00:00Z"},
+				"Example":{"class":"Tenant","web":{"class":"Application","vs":{"class":"Service_HTTP","virtualAddresses":["10.0.1.10"],"virtualPort":80,"pool":"web_pool"},"web_pool":{"class":"Pool","monitors":["http"]}}},
+				"Other":{"class":"Tenant","old":{"class":"Application"}}}`))
+		}
+	}
+
+	plan, err := s.Client.PlanAs3(`{"class":"AS3","action":"deploy","declaration":{"class":"ADC","schemaVersion":"3.50.0","id":"change-42",
+		"Example":{"class":"Tenant","web":{"class":"Application","vs":{"class":"Service_HTTP","virtualAddresses":["10.0.1.10"],"virtualPort":8080,"pool":"web_pool"},"web_pool":{"class":"Pool","monitors":["http"]}},"api":{"class":"Application"}},
+		"New":{"class":"Tenant","app":{"class":"Application"}},
+		"Other":{"class":"Tenant"}}}`)
+	assert.Nil(s.T(), err)
+	assert.Contains(s.T(), s.Requests[0], `dry-run`)
+	assert.Equal(s.T(), "GET /mgmt/shared/appsvcs/declare/Example,New,Other", s.Requests[2])
+	assert.Equal(s.T(), []string{"Example", "New"}, plan.DryRun.Succeeded())
+	assert.True(s.T(), plan.HasChanges())
+	assert.Equal(s.T(), "+ application /Example/api\n"+
+		"~ property /Example/web/vs/virtualPort: 80 -> 8080\n"+
+		"+ tenant /New\n"+
+		"- tenant /Other\n", plan.String())
+
+	out, err := json.Marshal(plan.Changes[1])
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), `{"action":"change","kind":"property","path":"/Example/web/vs/virtualPort","before":80,"after":8080}`, string(out))
+}
+
+func (s *As3TestSuite) TestPlanAs3NoChange() {
+	declared := `{"class":"ADC","schemaVersion":"3.50.0","Example":{"class":"Tenant","web":{"class":"Application","pool":{"class":"Pool"}}}}`
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST":
+			w.Write([]byte(`{"id":"t1"}`))
+		case strings.HasPrefix(r.URL.Path, "/mgmt/shared/appsvcs/task/"):
+			w.Write([]byte(`{"id":"t1","results":[{"code":200,"message":"no change","tenant":"Example","dryRun":true}]}`))
+		default:
+			w.Write([]byte(declared))
+		}
+	}
+
+	plan, err := s.Client.PlanAs3(`{"class":"AS3","declaration":` + declared + `}`)
+	assert.Nil(s.T(), err)
+	assert.False(s.T(), plan.HasChanges())
+
+	// AS3 has no content for tenants never deployed.
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST":
+			w.Write([]byte(`{"id":"t1"}`))
+		case strings.HasPrefix(r.URL.Path, "/mgmt/shared/appsvcs/task/"):
+			w.Write([]byte(`{"id":"t1","results":[{"code":200,"message":"success","tenant":"Example","dryRun":true}]}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+	plan, err = s.Client.PlanAs3(`{"class":"AS3","declaration":` + declared + `}`)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "+ tenant /Example\n", plan.String())
+
+	// A bare declaration is planned as AS3 would deploy it.
+	s.Requests = nil
+	plan, err = s.Client.PlanAs3(declared)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "+ tenant /Example\n", plan.String())
+	assert.Contains(s.T(), s.Requests[0], `{\"class\":\"AS3\",\"action\":\"dry-run\",\"declaration\":{\"Example\":`)
+}
+
+func TestDiffAs3Declarations(t *testing.T) {
+	parse := func(decl string) *As3Declaration {
+		var d As3Declaration
+		assert.Nil(t, json.Unmarshal([]byte(decl), &d))
+		return &d
+	}
+	deployed := parse(`{"class":"ADC","A":{"class":"Tenant","app":{"class":"Application"}},"B":{"class":"Tenant","app":{"class":"Application"}}}`)
+
+	changes, err := diffAs3Declarations(deployed, parse(`{"class":"ADC","updateMode":"complete","B":{"class":"Tenant","app":{"class":"Application"}},"C":{"class":"Tenant","app":{"class":"Application"}}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"remove /A", "add /C"}, []string{changes[0].Action + " " + changes[0].Path, changes[1].Action + " " + changes[1].Path})
+
+	changes, err = diffAs3Declarations(deployed, parse(`{"class":"ADC","A":{"class":"Tenant","app":{"class":"Application"}},"B":{"class":"Tenant","app":{"class":"Application"}}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(changes))
+}
+
+func TestAs3ValidatorBuiltIn(t *testing.T) {
+	v := NewAs3Validator()
+	assert.Nil(t, v.Validate(as3Declaration))
+
+	err := v.Validate(`{"class":"AS3","declaration":{"class":"ADC","schemaVersion":"3.x",
+		"Example":{"class":"Tenant","web":{"class":"Application",
+			"vs":{"class":"Service_HTTP","virtualPort":"80"},
+			"pool":{"class":"Pool","members":[{"serverAddresses":["192.0.2.10"]}]},
+			"other":{"label":"no class"},
+			"fw":{"class":"Firewall_Rule_List","rules":[]}}}}}`)
+	var invalid *SchemaError
+	assert.True(t, errors.As(err, &invalid))
+	assert.Equal(t, []SchemaProblem{
+		{"/declaration/Example/web/other", "missing property 'class'"},
+		{"/declaration/Example/web/pool/members/0", "missing property 'servicePort'"},
+		{"/declaration/Example/web/vs", "missing property 'virtualAddresses'"},
+		{"/declaration/Example/web/vs/virtualPort", "got string, want integer"},
+		{"/declaration/schemaVersion", `'3.x' does not match pattern '^[0-9]+\\.[0-9]+\\.[0-9]+$'`},
+	}, invalid.Problems)
+
+	err = v.Validate(`{"class":"ADC","Example":{"class":"Tenant"}}`)
+	assert.Equal(t, "AS3 declaration is invalid: /: missing property 'schemaVersion'", err.Error())
+	assert.NotNil(t, v.Validate(`{"class":"AS3","action":"deploy"}`))
+	assert.Nil(t, v.Validate(`{"class":"AS3","action":"remove"}`))
+}
+
+func TestAs3ValidatorSchemas(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "as3-schema-3.40.0-1.json"), []byte(`{
+		"$id": "https://example.com/as3-schema.json",
+		"type": "object",
+		"required": ["class", "declaration"],
+		"properties": {
+			"class": {"const": "AS3"},
+			"action": {"enum": ["deploy", "dry-run"]},
+			"declaration": {"$ref": "#/definitions/ADC"}
+		},
+		"additionalProperties": false,
+		"definitions": {
+			"ADC": {
+				"type": "object",
+				"required": ["schemaVersion"],
+				"properties": {"schemaVersion": {"type": "string"}},
+				"additionalProperties": {"anyOf": [{"type": "string"}, {"$ref": "#/definitions/Tenant"}]}
+			},
+			"Tenant": {"type": "object", "required": ["class"], "properties": {"class": {"const": "Tenant"}}}
+		}
+	}`), 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "as3-schema-3.50.0-5.json"), []byte(`true`), 0o644))
+
+	v, err := LoadAs3Validator(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"3.40.0", "3.50.0"}, v.Versions())
+
+	invalid := `{"class":"AS3","action":"remove","persist":true,"declaration":{"schemaVersion":"%s","Example":{"class":"Application"}}}`
+	err = v.Validate(fmt.Sprintf(invalid, "3.30.0"))
+	var validationErr *SchemaError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "3.40.0", validationErr.SchemaVersion)
+	assert.Contains(t, err.Error(), "/: additional properties 'persist' not allowed")
+	assert.Contains(t, err.Error(), "/action: value must be one of 'deploy', 'dry-run'")
+	assert.Contains(t, err.Error(), "/declaration/Example: 'anyOf' failed")
+
+	assert.Nil(t, v.Validate(fmt.Sprintf(invalid, "3.45.0")))
+	assert.Equal(t, `no AS3 schema supports schemaVersion "3.60.0", have 3.40.0, 3.50.0`, v.Validate(fmt.Sprintf(invalid, "3.60.0")).Error())
+	assert.Equal(t, `missing or invalid AS3 schemaVersion "3.x"`, v.Validate(fmt.Sprintf(invalid, "3.x")).Error())
+	assert.Equal(t, `missing or invalid AS3 schemaVersion ""`, v.Validate(`{"class":"ADC"}`).Error())
+
+	_, err = LoadAs3Validator(filepath.Join(dir, "missing.json"))
+	assert.NotNil(t, err)
+}
+
+// as3SchemaExcerpt follows the layout of the published AS3 schemas: class
+// dispatch with if/then, ECMAScript patterns, among them lookaheads Go's
+// regexp does not support, and custom formats.
+const as3SchemaExcerpt = `{
+	"$schema": "http://json-schema.org/draft-07/schema#",
+	"$id": "https://raw.githubusercontent.com/F5Networks/f5-appsvcs-extension/main/schema/latest/as3-schema.json",
+	"type": "object",
+	"required": ["class", "schemaVersion"],
+	"properties": {
+		"class": {"const": "ADC"},
+		"schemaVersion": {"type": "string", "pattern": "^3\\.(?<minor>[0-9]|[1-9][0-9])\\.[0-9]+$"},
+		"id": {"type": "string", "pattern": "^[^\\x00-\\x20\\u007f]{0,255}$"},
+		"label": {"type": "string", "pattern": "^[z-a]*$"}
+	},
+	"additionalProperties": {
+		"if": {"required": ["class"], "properties": {"class": {"const": "Tenant"}}},
+		"then": {"$ref": "#/definitions/Tenant"},
+		"else": {"type": ["string", "object"]}
+	},
+	"definitions": {
+		"Tenant": {
+			"type": "object",
+			"propertyNames": {"pattern": "^(?!Common$)[A-Za-z][0-9A-Za-z_.-]{0,47}$"},
+			"properties": {
+				"class": {"const": "Tenant"},
+				"defaultRouteDomain": {"type": "integer", "minimum": 0, "maximum": 65535}
+			},
+			"additionalProperties": {
+				"if": {"required": ["class"], "properties": {"class": {"const": "Application"}}},
+				"then": {"$ref": "#/definitions/Application"}
+			}
+		},
+		"Application": {
+			"type": "object",
+			"additionalProperties": {
+				"if": {"required": ["class"], "properties": {"class": {"const": "Service_HTTP"}}},
+				"then": {
+					"required": ["virtualAddresses"],
+					"properties": {
+						"virtualAddresses": {"type": "array", "items": {"type": "string", "format": "f5ip"}},
+						"virtualPort": {"type": "integer", "minimum": 0, "maximum": 65535},
+						"serverName": {"type": "string", "format": "hostname"}
+					}
+				}
+			}
+		}
+	}
+}`
+
+func TestAs3ValidatorRealSchema(t *testing.T) {
+	v := NewAs3Validator()
+	assert.Nil(t, v.AddSchema("3.50.0", []byte(as3SchemaExcerpt)))
+	assert.Equal(t, []string{
+		`format "f5ip"`,
+		"pattern \"^[z-a]*$\": error parsing regexp: [z-a] range in reverse order in `^[z-a]*$`",
+	}, v.Unchecked("3.50.0"))
+	assert.Nil(t, v.Unchecked(""))
+	assert.Nil(t, v.Unchecked("3.40.0"))
+
+	assert.Nil(t, v.Validate(`{"class":"ADC","schemaVersion":"3.50.0","id":"change-42",
+		"Example":{"class":"Tenant","web":{"class":"Application",
+			"vs":{"class":"Service_HTTP","virtualAddresses":["10.0.1.10"],"virtualPort":80,"serverName":"www.example.com"}}}}`))
+
+	err := v.Validate(`{"class":"ADC","schemaVersion":"3.50.0","id":"change 42","label":"unchecked",
+		"Example":{"class":"Tenant","defaultRouteDomain":-1,"web":{"class":"Application",
+			"vs":{"class":"Service_HTTP","virtualPort":80,"serverName":"www_example.com"}},
+			"Common":{"class":"Application"}}}`)
+	var invalid *SchemaError
+	if assert.True(t, errors.As(err, &invalid), "unexpected error: %v", err) {
+		assert.Equal(t, "3.50.0", invalid.SchemaVersion)
+		assert.Equal(t, []SchemaProblem{
+			{"/Example", "invalid propertyName 'Common'"},
+			{"/Example/defaultRouteDomain", "minimum: got -1, want 0"},
+			{"/Example/web/vs", "missing property 'virtualAddresses'"},
+			{"/Example/web/vs/serverName", "'www_example.com' is not valid hostname: invalid character '_'"},
+			{"/id", `'change 42' does not match pattern '^[^\\x00-\\x20\\u007f]{0,255}$'`},
+		}, invalid.Problems)
+	}
+	err = v.Validate(`{"class":"ADC","schemaVersion":"3.05.0"}`)
+	if assert.True(t, errors.As(err, &invalid), "unexpected error: %v", err) {
+		assert.Equal(t, "/schemaVersion", invalid.Problems[0].Path)
+	}
+}
+
+func (s *As3TestSuite) TestPostAs3BigipValidates() {
+	s.Client.As3Validator = NewAs3Validator()
+	defer func() { s.Client.As3Validator = nil }()
+
+	err, _, _ := s.Client.PostAs3Bigip(`{"class":"AS3","declaration":{"class":"ADC","Example":{"class":"Tenant"}}}`, "Example", "")
+	var invalid *SchemaError
+	assert.True(s.T(), errors.As(err, &invalid))
+	assert.Empty(s.T(), s.Requests)
+}
@@ -1,19 +1,17 @@
 package bigip
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
 )
 
-const doSchemaLatestURL = "https://raw.githubusercontent.com/F5Networks/terraform-provider-bigip/master/schemas/doschema.json"
-
 const (
 	uriSha          = "shared"
 	uriAppsvcs      = "appsvcs"
@@ -26,11 +24,6 @@ const (
 	uriApplications = "applications"
 )
 
-type doValidate struct {
-	doSchemaURL    string
-	doSchemaLatest string
-}
-
 type as3Version struct {
 	Version       string `json:"version"`
 	Release       string `json:"release"`
@@ -55,340 +48,178 @@ type Results1 struct {
 	RunTime   int64  `json:"runTime,omitempty"`
 }
 
-// PostPerAppBigIp - used for posting Per-Application Declarations
+// PostPerAppBigIp - used for posting Per-Application Declarations. It waits
+// for the AS3 task for at most 30 minutes.
 func (b *BigIP) PostPerAppBigIp(as3NewJson, tenantFilter, queryParam string) (error, string) {
-	// resp, err := PostPerApp()
+	ctx, cancel := b.as3Context()
+	defer cancel()
+	return b.PostPerAppBigIpContext(ctx, as3NewJson, tenantFilter, queryParam)
+}
+
+// PostPerAppBigIpContext is the context-aware variant of PostPerAppBigIp. Task polling stops once ctx is done.
+func (b *BigIP) PostPerAppBigIpContext(ctx context.Context, as3NewJson, tenantFilter, queryParam string) (error, string) {
+	b = b.WithContext(ctx)
 	async := "?async=true" + queryParam
-	resp, err := b.postAS3Req(as3NewJson, uriMgmt, uriShared, uriAppsvcs, uriDeclare, tenantFilter, uriApplications, async)
-	if err != nil {
+	result, err := b.runAs3Task(ctx, func() ([]byte, error) {
+		return b.postAS3Req(as3NewJson, uriMgmt, uriShared, uriAppsvcs, uriDeclare, tenantFilter, uriApplications, async)
+	})
+	if result == nil {
 		return err, ""
 	}
-	respRef := make(map[string]interface{})
-	json.Unmarshal(resp, &respRef)
-	respID := respRef["id"].(string)
-	taskStatus, err := b.getas3TaskStatus(respID)
-	respCode := taskStatus["results"].([]interface{})[0].(map[string]interface{})["code"].(float64)
-	log.Printf("[DEBUG]Per-App Deployment Code = %+v,ID = %+v", respCode, respID)
-
-	for respCode != 200 || taskStatus["results"].([]interface{})[0].(map[string]interface{})["message"].(string) != "success" {
-		log.Printf("[DEBUG]Per-App Deployment task status = %+v", taskStatus)
-		if taskStatus["results"].([]interface{})[0].(map[string]interface{})["message"].(string) == "no change" {
-			log.Printf("[DEBUG]Per-App Deployment task status = %+v", taskStatus)
-			break
-		}
-		taskStatus, _ = b.getas3TaskStatus(respID)
-		respCode = taskStatus["results"].([]interface{})[0].(map[string]interface{})["code"].(float64)
-		log.Printf("respCode: %v", respCode)
-		log.Printf("message: %v", taskStatus["results"].([]interface{})[0].(map[string]interface{})["message"].(string))
-		if err != nil {
-			return err, respID
-		}
-		if respCode == 503 || respCode >= 400 {
-			j, _ := json.MarshalIndent(taskStatus["results"].([]interface{}), "", "\t")
-			return fmt.Errorf("tenant Creation failed. Response: %+v", string(j)), respID
-		}
-		time.Sleep(3 * time.Second)
-	}
-	return nil, respID
+	return err, result.TaskID
 }
 
 /*
-PostAs3Bigip used for posting as3 json file to BIGIP
+PostAs3Bigip used for posting as3 json file to BIGIP. It waits for the AS3
+task for at most 30 minutes.
 */
 func (b *BigIP) PostAs3Bigip(as3NewJson, tenantFilter, queryParam string) (error, string, string) {
-	tenant := tenantFilter + "?async=true" + queryParam
+	ctx, cancel := b.as3Context()
+	defer cancel()
+	return b.PostAs3BigipContext(ctx, as3NewJson, tenantFilter, queryParam)
+}
 
-	successfulTenants := make([]string, 0)
-	resp, err := b.postReq(as3NewJson, uriMgmt, uriShared, uriAppsvcs, uriDeclare, tenant)
-	if err != nil {
+// PostAs3BigipContext is the context-aware variant of PostAs3Bigip. Task polling stops once ctx is done.
+// It returns the tenants that were deployed, also on an *As3PartialSuccessError, and the ID of the AS3 task.
+func (b *BigIP) PostAs3BigipContext(ctx context.Context, as3NewJson, tenantFilter, queryParam string) (error, string, string) {
+	result, err := b.PostAs3Result(ctx, as3NewJson, tenantFilter, queryParam)
+	if result == nil {
 		return err, "", ""
 	}
-	respRef := make(map[string]interface{})
-	json.Unmarshal(resp, &respRef)
-	respID := respRef["id"].(string)
-	taskStatus, err := b.getas3TaskStatus(respID)
-	respCode := taskStatus["results"].([]interface{})[0].(map[string]interface{})["code"].(float64)
-	log.Printf("[DEBUG]Code = %+v,ID = %+v", respCode, respID)
-	for respCode != 200 {
-		fastTask, err := b.getas3TaskStatus(respID)
-		if err != nil {
-			return err, "", respID
-		}
-		respCode = fastTask["results"].([]interface{})[0].(map[string]interface{})["code"].(float64)
-		if respCode != 0 && respCode != 503 {
-			tenant_list, tenant_count, _ := b.GetTenantList(as3NewJson)
-			if tenantCompare(tenant_list, tenantFilter) == 1 {
-				if len(fastTask["results"].([]interface{})) == 1 && fastTask["results"].([]interface{})[0].(map[string]interface{})["message"].(string) == "declaration is invalid" {
-					return fmt.Errorf("Error :%+v", fastTask["results"].([]interface{})[0].(map[string]interface{})["errors"]), "", respID
-				}
-				if len(fastTask["results"].([]interface{})) == 1 && fastTask["results"].([]interface{})[0].(map[string]interface{})["message"].(string) != "success" && fastTask["results"].([]interface{})[0].(map[string]interface{})["message"].(string) != "no change" {
-					j, _ := json.MarshalIndent(fastTask["results"].([]interface{}), "", "\t")
-					return fmt.Errorf("Tenant Creation failed with Response: %+v", string(j)), "", respID
-				}
-				i := tenant_count - 1
-				success_count := 0
-				for i >= 0 {
-					if fastTask["results"].([]interface{})[i].(map[string]interface{})["code"].(float64) == 200 {
-						successfulTenants = append(successfulTenants, fastTask["results"].([]interface{})[i].(map[string]interface{})["tenant"].(string))
-						success_count++
-					}
-					if fastTask["results"].([]interface{})[i].(map[string]interface{})["code"].(float64) >= 400 {
-						log.Printf("[ERROR] : HTTP %v :: %s for tenant %v", fastTask["results"].([]interface{})[i].(map[string]interface{})["code"].(float64), fastTask["results"].([]interface{})[i].(map[string]interface{})["message"].(string), fastTask["results"].([]interface{})[i].(map[string]interface{})["tenant"])
-					}
-					i = i - 1
-				}
-				if success_count == tenant_count {
-					log.Printf("[DEBUG]Sucessfully Created Application with ID  = %v", respID)
-					break // break here
-				} else if success_count == 0 {
-					j, _ := json.MarshalIndent(fastTask["results"].([]interface{}), "", "\t")
-					return fmt.Errorf("Tenant Creation failed. Response: %+v", string(j)), "", respID
-				} else {
-					finallist := strings.Join(successfulTenants[:], ",")
-					j, _ := json.MarshalIndent(fastTask["results"].([]interface{}), "", "\t")
-					return fmt.Errorf("as3 config post error response %+v", string(j)), finallist, respID
-				}
-			}
-			if respCode == 200 {
-				log.Printf("[DEBUG]Sucessfully Created Application with ID  = %v", respID)
-				break // break here
-			}
-			if respCode >= 400 {
-				j, _ := json.MarshalIndent(fastTask["results"].([]interface{}), "", "\t")
-				return fmt.Errorf("Tenant Creation failed. Response: %+v", string(j)), "", respID
-			}
-		}
-		if respCode == 503 {
-			taskIds, err := b.getas3Taskid()
-			if err != nil {
-				return err, "", respID
-			}
-			if len(taskIds) == 0 {
-				time.Sleep(2 * time.Second)
-				return b.PostAs3Bigip(as3NewJson, tenantFilter, queryParam)
-			}
-			for _, id := range taskIds {
-				if b.pollingStatus(id, 5*time.Second) {
-					return b.PostAs3Bigip(as3NewJson, tenantFilter, queryParam)
-				}
-			}
-		}
-		time.Sleep(3 * time.Second)
-	}
-	return nil, strings.Join(successfulTenants[:], ","), respID
+	return err, strings.Join(result.Succeeded(), ","), result.TaskID
 }
 
-func (b *BigIP) DeleteAs3Bigip(tenantName string) (error, string) {
-	tenant := tenantName + "?async=true"
-	failedTenants := make([]string, 0)
-	resp, err := b.deleteReq(uriMgmt, uriShared, uriAppsvcs, uriDeclare, tenant)
-	if err != nil {
-		return err, ""
-	}
-	respRef := make(map[string]interface{})
-	json.Unmarshal(resp, &respRef)
-	respID := respRef["id"].(string)
-	taskStatus, err := b.getas3Taskstatus(respID)
-	respCode := taskStatus.Results[0].Code
-	log.Printf("[DEBUG]Delete Code = %v,ID = %v", respCode, respID)
-	for respCode != 200 {
-		fastTask, err := b.getas3Taskstatus(respID)
-		if err != nil {
-			return err, ""
-		}
-		respCode = fastTask.Results[0].Code
-		if respCode != 0 && respCode != 503 {
-			tenant_count := len(strings.Split(tenantName, ","))
-			if tenant_count != 1 {
-				i := tenant_count - 1
-				success_count := 0
-				for i >= 0 {
-					if fastTask.Results[i].Code == 200 {
-						success_count++
-					}
-					if fastTask.Results[i].Code >= 400 {
-						failedTenants = append(failedTenants, fastTask.Results[i].Tenant)
-						log.Printf("[ERROR] : HTTP %d :: %s for tenant %v", fastTask.Results[i].Code, fastTask.Results[i].Message, fastTask.Results[i].Tenant)
-					}
-					i = i - 1
-				}
-				if success_count == tenant_count {
-					log.Printf("[DEBUG]Sucessfully Deleted Application with ID  = %v", respID)
-					break // break here
-				} else if success_count == 0 {
-					return errors.New(fmt.Sprintf("Tenant Deletion failed")), ""
-				} else {
-					finallist := strings.Join(failedTenants[:], ",")
-					return errors.New(fmt.Sprintf("Partial Success")), finallist
-				}
-			}
-			if respCode == 200 {
-				log.Printf("[DEBUG]Sucessfully Deleted Application with ID  = %v", respID)
-				break // break here
-			}
-			if respCode >= 400 {
-				j, _ := json.MarshalIndent(fastTask, "", "\t")
-				return fmt.Errorf("Tenant Deletion failed with Response: \n %+v", string(j)), ""
-			}
-		}
-		if respCode == 503 {
-			taskIds, err := b.getas3Taskid()
-			if err != nil {
-				return err, ""
-			}
-			if len(taskIds) == 0 {
-				time.Sleep(2 * time.Second)
-				return b.DeleteAs3Bigip(tenantName)
-			}
-			for _, id := range taskIds {
-				if b.pollingStatus(id, 5*time.Second) {
-					return b.DeleteAs3Bigip(tenantName)
-				}
-			}
+// PostAs3Result posts the AS3 declaration as3NewJson for the tenants in tenantFilter, or all of them when it is
+// empty, and waits for AS3 to process it. The declaration is checked by b.As3Validator first, if set. The result
+// is returned along with any error, such as an *As3PartialSuccessError, once the task has completed.
+func (b *BigIP) PostAs3Result(ctx context.Context, as3NewJson, tenantFilter, queryParam string) (*As3Result, error) {
+	b = b.WithContext(ctx)
+	if b.As3Validator != nil {
+		if err := b.As3Validator.Validate(as3NewJson); err != nil {
+			return nil, err
 		}
-		time.Sleep(3 * time.Second)
 	}
+	tenant := tenantFilter + "?async=true" + queryParam
+	return b.runAs3Task(ctx, func() ([]byte, error) {
+		return b.postReq(as3NewJson, uriMgmt, uriShared, uriAppsvcs, uriDeclare, tenant)
+	})
+}
 
-	return nil, ""
+// DeleteAs3Bigip removes the tenants in tenantName, a comma-separated list,
+// waiting for the AS3 task for at most 30 minutes.
+func (b *BigIP) DeleteAs3Bigip(tenantName string) (error, string) {
+	ctx, cancel := b.as3Context()
+	defer cancel()
+	return b.DeleteAs3BigipContext(ctx, tenantName)
+}
 
+// DeleteAs3BigipContext is the context-aware variant of DeleteAs3Bigip. Task polling stops once ctx is done.
+// On an *As3PartialSuccessError it returns the tenants that failed to be deleted.
+func (b *BigIP) DeleteAs3BigipContext(ctx context.Context, tenantName string) (error, string) {
+	b = b.WithContext(ctx)
+	tenant := tenantName + "?async=true"
+	result, err := b.runAs3Task(ctx, func() ([]byte, error) {
+		return b.deleteReq(uriMgmt, uriShared, uriAppsvcs, uriDeclare, tenant)
+	})
+	var partial *As3PartialSuccessError
+	if errors.As(err, &partial) {
+		return err, strings.Join(result.Failed(), ",")
+	}
+	return err, ""
 }
-func (b *BigIP) ModifyAs3(tenantFilter string, as3_json string) error {
-	tenant := tenantFilter + "?async=true"
-	resp, err := b.fastPatch(as3_json, uriMgmt, uriShared, uriAppsvcs, uriDeclare, tenant)
-	if err != nil {
-		return err
-	}
-	respRef := make(map[string]interface{})
-	json.Unmarshal(resp, &respRef)
-	respID := respRef["id"].(string)
-	taskStatus, err := b.getas3Taskstatus(respID)
-	respCode := taskStatus.Results[0].Code
-	for respCode != 200 {
-		fastTask, err := b.getas3Taskstatus(respID)
-		if err != nil {
-			return err
-		}
-		respCode = fastTask.Results[0].Code
-		if respCode == 200 {
-			log.Printf("[DEBUG]Sucessfully Modified Application with ID  = %v", respID)
-			break // break here
-		}
-		if respCode == 503 {
-			taskIds, err := b.getas3Taskid()
-			if err != nil {
-				return err
-			}
-			for _, id := range taskIds {
-				if b.pollingStatus(id, 5*time.Second) {
-					return b.ModifyAs3(tenantFilter, as3_json)
-				}
-			}
-		}
-	}
 
-	return nil
+// ModifyAs3 patches the declaration of the tenants in tenantFilter with
+// as3_json, waiting for the AS3 task for at most 30 minutes.
+func (b *BigIP) ModifyAs3(tenantFilter string, as3_json string) error {
+	ctx, cancel := b.as3Context()
+	defer cancel()
+	return b.ModifyAs3Context(ctx, tenantFilter, as3_json)
+}
 
+// ModifyAs3Context is the context-aware variant of ModifyAs3. Task polling stops once ctx is done.
+func (b *BigIP) ModifyAs3Context(ctx context.Context, tenantFilter string, as3_json string) error {
+	b = b.WithContext(ctx)
+	tenant := tenantFilter + "?async=true"
+	_, err := b.runAs3Task(ctx, func() ([]byte, error) {
+		return b.fastPatch(as3_json, uriMgmt, uriShared, uriAppsvcs, uriDeclare, tenant)
+	})
+	return err
 }
-func (b *BigIP) GetAs3(name, appList string, perAppMode bool) (string, error) {
-	as3Json := make(map[string]interface{})
-	adcJson := make(map[string]interface{})
-	var err error
-	var ok bool
 
-	log.Printf("[DEBUG] (GetAs3) Per App Mode :%+v", perAppMode)
+func (b *BigIP) GetAs3(name, appList string, perAppMode bool) (string, error) {
+	b.log().Debug("reading AS3 declaration", "tenant", name, "per_app", perAppMode)
 
 	if perAppMode {
-		err, ok = b.getForEntity(&adcJson, uriMgmt, uriShared, uriAppsvcs, uriDeclare, name, uriApplications)
-	} else {
-		as3Json["class"] = "AS3"
-		as3Json["action"] = "deploy"
-		as3Json["persist"] = true
-		err, ok = b.getForEntity(&adcJson, uriMgmt, uriShared, uriAppsvcs, uriDeclare, name)
+		var apps As3Tenant
+		err, ok := b.getAs3Entity(&apps, uriMgmt, uriShared, uriAppsvcs, uriDeclare, name, uriApplications)
+		if err != nil || !ok {
+			return "", err
+		}
+		delete(apps.Extra, "updateMode")
+		delete(apps.Extra, "controls")
+		out, err := json.Marshal(&apps)
+		if err != nil {
+			return "", err
+		}
+		return string(out), nil
 	}
-	if err != nil {
+
+	var decl As3Declaration
+	err, ok := b.getAs3Entity(&decl, uriMgmt, uriShared, uriAppsvcs, uriDeclare, name)
+	if err != nil || !ok {
 		return "", err
 	}
-	if !ok {
-		return "", nil
-	}
-	delete(adcJson, "updateMode")
-	delete(adcJson, "controls")
-
-	if perAppMode {
-		as3Json = adcJson
-	} else {
-		as3Json["declaration"] = adcJson
-	}
-	out, _ := json.Marshal(as3Json)
-	as3String := string(out)
-	tenantList := strings.Split(appList, ",")
-	found := 0
-	for _, item := range tenantList {
+	decl.UpdateMode = ""
+	decl.Controls = nil
+	sharedListed := false
+	for _, item := range strings.Split(appList, ",") {
 		if item == "Shared" && name == "Common" {
-			found = 1
+			sharedListed = true
 		}
 	}
-	if found == 0 {
-		sharedTenant := ""
-		resp := []byte(as3String)
-		jsonRef := make(map[string]interface{})
-		json.Unmarshal(resp, &jsonRef)
-		for key, value := range jsonRef {
-			if rec, ok := value.(map[string]interface{}); ok && key == "declaration" {
-				for k, v := range rec {
-					if rec2, ok := v.(map[string]interface{}); ok {
-						for k1, v1 := range rec2 {
-							if _, ok := v1.(map[string]interface{}); ok {
-								if k1 == "Shared" {
-									sharedTenant = k
-								}
-							}
-						}
-					}
-					if sharedTenant == "Common" && sharedTenant != name {
-						// Removing delete call for shared tenant to address Issue #869
-						// delete(rec, sharedTenant)
-						log.Printf("[DEBUG]Shared Tenant:%+v", sharedTenant)
-					}
-				}
-			}
+	if common, ok := decl.Tenants["Common"]; ok && !sharedListed && name != "Common" {
+		if _, ok := common.Applications["Shared"]; ok {
+			// Removing the shared tenant was dropped to address Issue #869
+			b.log().Debug("keeping shared tenant in AS3 declaration", "tenant", "Common")
 		}
-		out, _ = json.Marshal(jsonRef)
-		as3String = string(out)
 	}
-	return as3String, nil
+	req := NewAs3Request("")
+	req.Declaration = &decl
+	return req.JSON()
 }
-func (b *BigIP) getAs3version() (*as3Version, error) {
-	var as3Ver as3Version
-	err, _ := b.getForEntity(&as3Ver, uriMgmt, uriShared, uriAppsvcs, uriInfo)
+
+// getAs3Entity is getForEntity for AS3 declarations, which AS3 answers with
+// no content when it has none for the tenant.
+func (b *BigIP) getAs3Entity(e interface{}, path ...string) (error, bool) {
+	resp, err := b.APICall(&APIRequest{
+		Method:      "get",
+		URL:         b.iControlPath(path),
+		ContentType: "application/json",
+	})
 	if err != nil {
-		return nil, err
+		return err, false
 	}
-	return &as3Ver, nil
-}
-func (b *BigIP) getas3Taskstatus(id string) (*As3TaskType, error) {
-	var taskList As3TaskType
-	err, _ := b.getForEntity(&taskList, uriMgmt, uriShared, uriAppsvcs, uriTask, id)
-	if err != nil {
-		return nil, err
+	if len(bytes.TrimSpace(resp)) == 0 {
+		return nil, false
 	}
-	return &taskList, nil
+	if err := json.Unmarshal(resp, e); err != nil {
+		return err, false
+	}
+	return nil, true
 }
-func (b *BigIP) getas3TaskStatus(id string) (map[string]interface{}, error) {
-	var taskList map[string]interface{}
-	err, _ := b.getForEntity(&taskList, uriMgmt, uriShared, uriAppsvcs, uriTask, id)
+
+func (b *BigIP) getAs3version() (*as3Version, error) {
+	var as3Ver as3Version
+	err, _ := b.getForEntity(&as3Ver, uriMgmt, uriShared, uriAppsvcs, uriInfo)
 	if err != nil {
 		return nil, err
 	}
-	return taskList, nil
+	return &as3Ver, nil
 }
-
 func (b *BigIP) Getas3TaskResponse(id string) (interface{}, error) {
-	as3Json := make(map[string]interface{})
-	as3Json["class"] = "AS3"
-	as3Json["action"] = "deploy"
-	as3Json["persist"] = true
-	var taskResponse map[string]interface{}
+	var taskResponse struct {
+		Declaration *As3Declaration `json:"declaration"`
+	}
 	err, ok := b.getForEntity(&taskResponse, uriMgmt, uriShared, uriAppsvcs, uriTask, id)
 	if err != nil {
 		return nil, err
@@ -396,13 +227,16 @@ func (b *BigIP) Getas3TaskResponse(id string) (interface{}, error) {
 	if !ok {
 		return nil, nil
 	}
-	delete(taskResponse["declaration"].(map[string]interface{}), "updateMode")
-	delete(taskResponse["declaration"].(map[string]interface{}), "controls")
-	delete(taskResponse["declaration"].(map[string]interface{}), "id")
-	as3Json["declaration"] = taskResponse["declaration"]
-	out, _ := json.Marshal(as3Json)
-	as3String := string(out)
-	return as3String, nil
+	if taskResponse.Declaration == nil {
+		return nil, fmt.Errorf("AS3 task %s has no declaration", id)
+	}
+	decl := taskResponse.Declaration
+	decl.UpdateMode = ""
+	decl.Controls = nil
+	decl.ID = ""
+	req := NewAs3Request("")
+	req.Declaration = decl
+	return req.JSON()
 }
 
 func (b *BigIP) getas3Taskid() ([]string, error) {
@@ -412,119 +246,61 @@ func (b *BigIP) getas3Taskid() ([]string, error) {
 	if err != nil {
 		return taskIDs, err
 	}
-	for l := range taskList.Items {
-		if taskList.Items[l].Results[0].Message == "in progress" {
-			taskIDs = append(taskIDs, taskList.Items[l].ID)
+	for _, task := range taskList.Items {
+		if len(task.Results) > 0 && task.Results[0].Message == "in progress" {
+			taskIDs = append(taskIDs, task.ID)
 		}
 	}
 	return taskIDs, nil
 }
 
-func (b *BigIP) pollingStatus(id string, backoff time.Duration) bool {
-	log.Printf("[INFO]pollingStatus DELAY -- %d ", int(backoff.Seconds()))
-	var taskList As3TaskType
-	err, _ := b.getForEntity(&taskList, uriMgmt, uriShared, uriAppsvcs, uriTask, id)
-	if err != nil {
-		return false
-	}
-	if taskList.Results[0].Code != 200 {
-		if backoff > 30*time.Second {
-			backoff = 30 * time.Second // cap at 30 seconds
-		}
-		time.Sleep(backoff)
-		return b.pollingStatus(id, backoff*2) // recursive call with doubled delay
-	}
-
-	return true
-}
-
+// GetTenantList returns the tenants of the AS3 declaration in body, their
+// number, and the applications of those tenants, as comma-separated lists.
 func (b *BigIP) GetTenantList(body interface{}) (string, int, string) {
-	tenantList := make([]string, 0)
-	applicationList := make([]string, 0)
-	as3json := body.(string)
-	resp := []byte(as3json)
-	jsonRef := make(map[string]interface{})
-	json.Unmarshal(resp, &jsonRef)
-	for key, value := range jsonRef {
-		if rec, ok := value.(map[string]interface{}); ok && key == "declaration" {
-			for k, v := range rec {
-				if rec2, ok := v.(map[string]interface{}); ok {
-					found := 0
-					for k1, v1 := range rec2 {
-						if k1 == "class" && v1 == "Tenant" {
-							found = 1
-						}
-						if rec3, ok := v1.(map[string]interface{}); ok {
-							found1 := 0
-							for k2, v2 := range rec3 {
-								if k2 == "class" && v2 == "Application" {
-									found1 = 1
-								}
-							}
-							if found1 == 1 {
-								applicationList = append(applicationList, k1)
-							}
-
-						}
-					}
-					if found == 1 {
-						tenantList = append(tenantList, k)
-					}
-				}
-			}
-		}
+	as3json, _ := body.(string)
+	req, err := ParseAs3Request(as3json)
+	if err != nil || req.Declaration == nil {
+		return "", 0, ""
+	}
+	tenants := req.Declaration.TenantNames()
+	var applications []string
+	for _, name := range tenants {
+		applications = append(applications, req.Declaration.Tenants[name].ApplicationNames()...)
 	}
-	finalTenantlist := strings.Join(tenantList[:], ",")
-	finalApplicationList := strings.Join(applicationList[:], ",")
-	return finalTenantlist, len(tenantList), finalApplicationList
+	return strings.Join(tenants, ","), len(tenants), strings.Join(applications, ",")
 }
 
+// GetAppsList returns the applications of the per-application declaration
+// in body as a comma-separated list.
 func (b *BigIP) GetAppsList(body interface{}) string {
-	//tenantList := make([]string, 0)
-	appList := make([]string, 0)
-	as3json := body.(string)
-	resp := []byte(as3json)
-	jsonRef := make(map[string]interface{})
-	json.Unmarshal(resp, &jsonRef)
-	for key, value := range jsonRef {
-		//check value is of interface type
-		if _, ok := value.(map[string]interface{}); ok {
-			//check for class matches to Application
-			//range over the map and check if key is class and value is Application
-			for k1, v1 := range value.(map[string]interface{}) {
-				//check for class matches to Application
-				if k1 == "class" && v1 == "Application" {
-					appList = append(appList, key)
-				}
-			}
-		}
+	as3json, _ := body.(string)
+	var apps As3Tenant
+	if err := json.Unmarshal([]byte(as3json), &apps); err != nil {
+		return ""
 	}
-	finalApplicationList := strings.Join(appList[:], ",")
-	return finalApplicationList
+	return strings.Join(apps.ApplicationNames(), ",")
 }
+
+// GetTarget returns the address of the target of the AS3 declaration in
+// body, or "" if it has none.
 func (b *BigIP) GetTarget(body interface{}) string {
-	as3json := body.(string)
-	resp := []byte(as3json)
-	jsonRef := make(map[string]interface{})
-	json.Unmarshal(resp, &jsonRef)
-	for key, value := range jsonRef {
-		if _, ok := value.(map[string]interface{}); ok && key == "declaration" {
-			if val, ok := value.(map[string]interface{})["target"]; ok {
-				//log.Printf("[DEBUG]: target:%+v", val.(map[string]interface{})["address"])
-				return val.(map[string]interface{})["address"].(string)
-			}
-		}
+	as3json, _ := body.(string)
+	req, err := ParseAs3Request(as3json)
+	if err != nil || req.Declaration == nil || req.Declaration.Target == nil {
+		return ""
 	}
-	return ""
+	return req.Declaration.Target.Address
 }
 
+// AddTeemAgent sets the user agent of the client in the controls of the AS3
+// declaration in body, on AS3 versions that support it, and returns the
+// declaration.
 func (b *BigIP) AddTeemAgent(body interface{}) (string, error) {
-	var s string
-	as3json := body.(string)
-	resp := []byte(as3json)
-	jsonRef := make(map[string]interface{})
-	json.Unmarshal(resp, &jsonRef)
-	//jsonRef["controls"] = map[string]interface{}{"class": "Controls", "userAgent": "Terraform Configured AS3"}
+	as3json, _ := body.(string)
+	req, err := ParseAs3Request(as3json)
+	if err != nil {
+		return "", err
+	}
 	as3ver, err := b.getAs3version()
 	if err != nil {
 		return "", fmt.Errorf("Getting AS3 Version failed with %v", err)
@@ -532,27 +308,16 @@ func (b *BigIP) AddTeemAgent(body interface{}) (string, error) {
 	if as3ver.Version == "" {
 		return "", fmt.Errorf("Getting AS3 Version failed,please check AS3 installed?")
 	}
-	log.Printf("[DEBUG] AS3 Version:%+v", as3ver.Version)
-	log.Printf("[DEBUG] Terraform Version:%+v", b.UserAgent)
-	//userAgent, err := getVersion("/usr/local/bin/terraform")
-	//log.Printf("[DEBUG] Terraform version:%+v", userAgent)
+	b.log().Debug("adding AS3 controls", "as3_version", as3ver.Version, "user_agent", b.UserAgent)
 	res1 := strings.Split(as3ver.Version, ".")
-	for key, value := range jsonRef {
-		if key == "declaration" {
-			if rec, ok := value.(map[string]interface{}); ok {
-				if intConvert(res1[0]) > 3 || intConvert(res1[1]) >= 18 {
-					rec["controls"] = map[string]interface{}{"class": "Controls", "userAgent": b.UserAgent}
-				}
-			}
+	if req.Declaration != nil && len(res1) > 1 && (intConvert(res1[0]) > 3 || intConvert(res1[1]) >= 18) {
+		if req.Declaration.Controls == nil {
+			req.Declaration.Controls = &As3Controls{}
 		}
+		req.Declaration.Controls.Class = "Controls"
+		req.Declaration.Controls.UserAgent = b.UserAgent
 	}
-	jsonData, err := json.Marshal(jsonRef)
-	if err != nil {
-		//log.Println(err)
-		return "", fmt.Errorf("Getting AS3 Version failed with %v", err)
-	}
-	s = string(jsonData)
-	return s, nil
+	return req.JSON()
 }
 
 func (b *BigIP) CheckSetting() (bool, error) {
@@ -568,7 +333,7 @@ func (b *BigIP) CheckSetting() (bool, error) {
 	} else if value, ok := respRef["perAppDeploymentAllowed"]; ok { // for As3 version 3.5
 		perAppDeploymentAllowed = value.(bool)
 	}
-	log.Printf("[INFO] BigIP Setting perAppDeploymentAllowed:%+v", perAppDeploymentAllowed)
+	b.log().Debug("AS3 settings", "per_app_deployment_allowed", perAppDeploymentAllowed)
 	return perAppDeploymentAllowed, nil
 
 	// err, setting := b.getSetting(uriMgmt, uriShared, uriAppsvcs, uriSetting)
@@ -598,7 +363,7 @@ func (b *BigIP) AddServiceDiscoveryNodes(taskid string, config []interface{}) er
 	respRef := make(map[string]interface{})
 	json.Unmarshal(resp, &respRef)
 	//respID := respRef["id"].(string)
-	log.Printf("[INFO] Response:%+v", respRef)
+	b.log().Debug("service discovery nodes added", "task_id", taskid, "response", respRef)
 	return nil
 }
 
@@ -633,6 +398,12 @@ func getVersion(tfBinary string) (string, error) {
 	return ua, nil
 }
 func (b *BigIP) TenantDifference(slice1 []string, slice2 []string) string {
+	return tenantDifference(slice1, slice2)
+}
+
+// tenantDifference returns the tenants of slice1 missing from slice2, as a
+// comma-separated list.
+func tenantDifference(slice1 []string, slice2 []string) string {
 	var diff []string
 	for _, s1 := range slice1 {
 		found := false
@@ -649,11 +420,3 @@ func (b *BigIP) TenantDifference(slice1 []string, slice2 []string) string {
 	diff_tenant_list := strings.Join(diff[:], ",")
 	return diff_tenant_list
 }
-func tenantCompare(t1 string, t2 string) int {
-	tenantList1 := strings.Split(t1, ",")
-	tenantList2 := strings.Split(t2, ",")
-	if len(tenantList1) == len(tenantList2) {
-		return 1
-	}
-	return 0
-}
@@ -0,0 +1,731 @@
+package bigip
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// The types below model AS3 declarations. Each type knows the properties it
+// has fields for and keeps any other property verbatim in Extra, and classes
+// without a dedicated type are kept as As3Object, so that parsing then
+// marshalling a declaration gives it back unchanged.
+
+// As3Request is the AS3 class wrapping an ADC declaration with the action to
+// take on it.
+type As3Request struct {
+	Class string `json:"class,omitempty"`
+	// Action is "deploy", "dry-run", "redeploy", "retrieve", "remove" or
+	// "patch".
+	Action      string          `json:"action,omitempty"`
+	Persist     *bool           `json:"persist,omitempty"`
+	SyncToGroup string          `json:"syncToGroup,omitempty"`
+	Declaration *As3Declaration `json:"declaration,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// As3Declaration is an ADC declaration: its tenants and their settings.
+type As3Declaration struct {
+	Class         string       `json:"class,omitempty"`
+	SchemaVersion string       `json:"schemaVersion,omitempty"`
+	ID            string       `json:"id,omitempty"`
+	Label         string       `json:"label,omitempty"`
+	Remark        string       `json:"remark,omitempty"`
+	UpdateMode    string       `json:"updateMode,omitempty"`
+	Target        *As3Target   `json:"target,omitempty"`
+	Controls      *As3Controls `json:"controls,omitempty"`
+	// Tenants holds the objects of class Tenant, by name.
+	Tenants map[string]*As3Tenant `json:"-"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// As3Target is the device a declaration is for, when it is sent through
+// BIG-IQ.
+type As3Target struct {
+	Address  string `json:"address,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// As3Controls is the Controls class of a declaration.
+type As3Controls struct {
+	Class     string `json:"class,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+	LogLevel  string `json:"logLevel,omitempty"`
+	Trace     *bool  `json:"trace,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// As3Tenant is the Tenant class. Per-application declarations, which list
+// applications without a tenant around them, have the same shape.
+type As3Tenant struct {
+	Class  string `json:"class,omitempty"`
+	Label  string `json:"label,omitempty"`
+	Remark string `json:"remark,omitempty"`
+	// Applications holds the objects of class Application, by name.
+	Applications map[string]*As3Application `json:"-"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// As3Application is the Application class.
+type As3Application struct {
+	Class string `json:"class,omitempty"`
+	// Template is only used by schema versions before 3.20.
+	Template string `json:"template,omitempty"`
+	Label    string `json:"label,omitempty"`
+	Remark   string `json:"remark,omitempty"`
+	// Items holds the objects of the application, by name: *As3Service,
+	// *As3Pool, *As3Monitor, *As3TLSServer, *As3Certificate, *As3IRule, or
+	// *As3Object for the other classes.
+	Items map[string]As3Item `json:"-"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// As3Item is an object of an application.
+type As3Item interface {
+	// As3Class returns the AS3 class of the object.
+	As3Class() string
+}
+
+// As3Pointer is a property that is either a plain string, such as the name
+// of an object of the application or PEM data, or an object pointing at the
+// value: {"use": "<AS3 path>"} or {"bigip": "<BIG-IP path>"}.
+type As3Pointer struct {
+	Value string
+	Use   string
+	BigIP string
+	// Raw holds any other form, such as {"base64": "..."} or
+	// {"url": "..."}, as is.
+	Raw json.RawMessage
+}
+
+// As3Service is the Service_HTTP or Service_HTTPS class.
+type As3Service struct {
+	Class  string `json:"class"`
+	Label  string `json:"label,omitempty"`
+	Remark string `json:"remark,omitempty"`
+	// VirtualAddresses lists addresses, such as "10.0.1.10", or [address,
+	// allowed source] pairs.
+	VirtualAddresses []interface{} `json:"virtualAddresses,omitempty"`
+	VirtualPort      int           `json:"virtualPort,omitempty"`
+	Pool             *As3Pointer   `json:"pool,omitempty"`
+	ServerTLS        *As3Pointer   `json:"serverTLS,omitempty"`
+	ClientTLS        *As3Pointer   `json:"clientTLS,omitempty"`
+	IRules           []As3Pointer  `json:"iRules,omitempty"`
+	Snat             interface{}   `json:"snat,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// As3Pool is the Pool class.
+type As3Pool struct {
+	Class             string          `json:"class"`
+	Label             string          `json:"label,omitempty"`
+	Remark            string          `json:"remark,omitempty"`
+	LoadBalancingMode string          `json:"loadBalancingMode,omitempty"`
+	Monitors          []As3Pointer    `json:"monitors,omitempty"`
+	Members           []As3PoolMember `json:"members,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// As3PoolMember is a member of an As3Pool.
+type As3PoolMember struct {
+	ServicePort      int      `json:"servicePort,omitempty"`
+	ServerAddresses  []string `json:"serverAddresses,omitempty"`
+	AddressDiscovery string   `json:"addressDiscovery,omitempty"`
+	ShareNodes       *bool    `json:"shareNodes,omitempty"`
+	Enable           *bool    `json:"enable,omitempty"`
+	// AdminState is "enable", "disable" or "offline".
+	AdminState string `json:"adminState,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// As3Monitor is the Monitor class.
+type As3Monitor struct {
+	Class  string `json:"class"`
+	Label  string `json:"label,omitempty"`
+	Remark string `json:"remark,omitempty"`
+	// MonitorType is for instance "http", "https", "tcp" or "icmp".
+	MonitorType string `json:"monitorType,omitempty"`
+	Interval    int    `json:"interval,omitempty"`
+	Timeout     int    `json:"timeout,omitempty"`
+	Send        string `json:"send,omitempty"`
+	Receive     string `json:"receive,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// As3TLSServer is the TLS_Server class, the client-side TLS profile of a
+// service.
+type As3TLSServer struct {
+	Class        string              `json:"class"`
+	Label        string              `json:"label,omitempty"`
+	Remark       string              `json:"remark,omitempty"`
+	Certificates []As3TLSCertificate `json:"certificates,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// As3TLSCertificate refers to an As3Certificate from an As3TLSServer.
+type As3TLSCertificate struct {
+	Certificate string `json:"certificate,omitempty"`
+	MatchToSNI  string `json:"matchToSNI,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// As3Certificate is the Certificate class.
+type As3Certificate struct {
+	Class       string      `json:"class"`
+	Label       string      `json:"label,omitempty"`
+	Remark      string      `json:"remark,omitempty"`
+	Certificate *As3Pointer `json:"certificate,omitempty"`
+	PrivateKey  *As3Pointer `json:"privateKey,omitempty"`
+	ChainCA     *As3Pointer `json:"chainCA,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// As3IRule is the iRule class.
+type As3IRule struct {
+	Class  string `json:"class"`
+	Label  string `json:"label,omitempty"`
+	Remark string `json:"remark,omitempty"`
+	// IRule is the TCL source, or where to take it from.
+	IRule *As3Pointer `json:"iRule,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// As3Object is an object of a class without a dedicated type. Its
+// properties are kept as is.
+type As3Object struct {
+	Class      string
+	Properties map[string]json.RawMessage
+}
+
+func (s *As3Service) As3Class() string     { return s.Class }
+func (p *As3Pool) As3Class() string        { return "Pool" }
+func (m *As3Monitor) As3Class() string     { return "Monitor" }
+func (t *As3TLSServer) As3Class() string   { return "TLS_Server" }
+func (c *As3Certificate) As3Class() string { return "Certificate" }
+func (r *As3IRule) As3Class() string       { return "iRule" }
+func (o *As3Object) As3Class() string      { return o.Class }
+
+// NewAs3Request returns a request deploying an empty declaration of
+// schemaVersion, to fill with Tenant and Application.
+func NewAs3Request(schemaVersion string) *As3Request {
+	persist := true
+	return &As3Request{
+		Class:   "AS3",
+		Action:  "deploy",
+		Persist: &persist,
+		Declaration: &As3Declaration{
+			Class:         "ADC",
+			SchemaVersion: schemaVersion,
+			Tenants:       map[string]*As3Tenant{},
+		},
+	}
+}
+
+// ParseAs3Request parses an AS3 request, such as the body of PostAs3Bigip.
+func ParseAs3Request(as3Json string) (*As3Request, error) {
+	var r As3Request
+	if err := json.Unmarshal([]byte(as3Json), &r); err != nil {
+		return nil, fmt.Errorf("invalid AS3 declaration: %w", err)
+	}
+	return &r, nil
+}
+
+// JSON returns the request as the JSON string the AS3 functions take.
+func (r *As3Request) JSON() (string, error) {
+	out, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// Tenant returns the tenant called name, adding it if needed.
+func (d *As3Declaration) Tenant(name string) *As3Tenant {
+	if d.Tenants == nil {
+		d.Tenants = map[string]*As3Tenant{}
+	}
+	t, ok := d.Tenants[name]
+	if !ok {
+		t = &As3Tenant{Class: "Tenant", Applications: map[string]*As3Application{}}
+		d.Tenants[name] = t
+	}
+	return t
+}
+
+// TenantNames returns the names of the tenants, sorted.
+func (d *As3Declaration) TenantNames() []string {
+	return sortedKeys(d.Tenants)
+}
+
+// Application returns the application called name, adding it if needed.
+func (t *As3Tenant) Application(name string) *As3Application {
+	if t.Applications == nil {
+		t.Applications = map[string]*As3Application{}
+	}
+	a, ok := t.Applications[name]
+	if !ok {
+		a = &As3Application{Class: "Application", Items: map[string]As3Item{}}
+		t.Applications[name] = a
+	}
+	return a
+}
+
+// ApplicationNames returns the names of the applications, sorted.
+func (t *As3Tenant) ApplicationNames() []string {
+	return sortedKeys(t.Applications)
+}
+
+// Add sets the object called name of the application to item.
+func (a *As3Application) Add(name string, item As3Item) *As3Application {
+	if a.Items == nil {
+		a.Items = map[string]As3Item{}
+	}
+	a.Items[name] = item
+	return a
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// unmarshalAs3 decodes data into v, a pointer to a struct, and returns the
+// properties v has no field for. Unlike json.Unmarshal, a property only sets
+// the field whose name matches its case exactly, since AS3 names such as
+// "Target" or "Remark" are those of tenants and applications. Properties
+// with a field but set to a value omitted when v is marshalled, such as "",
+// are returned too, so that they are not lost.
+func unmarshalAs3(data []byte, v interface{}) (map[string]json.RawMessage, error) {
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	fields := as3FieldNames(reflect.TypeOf(v).Elem())
+	known := make(map[string]json.RawMessage, len(fields))
+	for k, raw := range all {
+		if fields[k] {
+			known[k] = raw
+		}
+	}
+	if len(known) > 0 {
+		data, err := json.Marshal(known)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, v); err != nil {
+			return nil, err
+		}
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var kept map[string]json.RawMessage
+	if err := json.Unmarshal(out, &kept); err != nil {
+		return nil, err
+	}
+	for k := range kept {
+		delete(all, k)
+	}
+	return all, nil
+}
+
+// as3FieldNames returns the JSON names of the fields of the struct type t.
+func as3FieldNames(t reflect.Type) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// marshalAs3 encodes v, a pointer to a struct, with the properties in extra.
+func marshalAs3(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	for k, raw := range extra {
+		if _, ok := all[k]; !ok {
+			all[k] = raw
+		}
+	}
+	return json.Marshal(all)
+}
+
+// as3ClassOf returns the class of the object in data, or "" if data is not
+// an object with a class.
+func as3ClassOf(data json.RawMessage) string {
+	var obj map[string]json.RawMessage
+	if len(data) == 0 || data[0] != '{' || json.Unmarshal(data, &obj) != nil {
+		return ""
+	}
+	var class string
+	json.Unmarshal(obj["class"], &class)
+	return class
+}
+
+// withChildren returns extra with the children added, to marshal a
+// container.
+func withChildren[V any](extra map[string]json.RawMessage, children map[string]V) (map[string]json.RawMessage, error) {
+	all := make(map[string]json.RawMessage, len(extra)+len(children))
+	for k, raw := range extra {
+		all[k] = raw
+	}
+	for k, child := range children {
+		raw, err := json.Marshal(child)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		all[k] = raw
+	}
+	return all, nil
+}
+
+func (r *As3Request) UnmarshalJSON(data []byte) error {
+	type alias As3Request
+	extra, err := unmarshalAs3(data, (*alias)(r))
+	r.Extra = extra
+	return err
+}
+
+func (r As3Request) MarshalJSON() ([]byte, error) {
+	type alias As3Request
+	return marshalAs3((*alias)(&r), r.Extra)
+}
+
+func (d *As3Declaration) UnmarshalJSON(data []byte) error {
+	type alias As3Declaration
+	extra, err := unmarshalAs3(data, (*alias)(d))
+	if err != nil {
+		return err
+	}
+	d.Tenants = map[string]*As3Tenant{}
+	for k, raw := range extra {
+		if as3ClassOf(raw) != "Tenant" {
+			continue
+		}
+		var t As3Tenant
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return fmt.Errorf("tenant %s: %w", k, err)
+		}
+		d.Tenants[k] = &t
+		delete(extra, k)
+	}
+	d.Extra = extra
+	return nil
+}
+
+func (d As3Declaration) MarshalJSON() ([]byte, error) {
+	type alias As3Declaration
+	all, err := withChildren(d.Extra, d.Tenants)
+	if err != nil {
+		return nil, err
+	}
+	return marshalAs3((*alias)(&d), all)
+}
+
+func (t *As3Target) UnmarshalJSON(data []byte) error {
+	type alias As3Target
+	extra, err := unmarshalAs3(data, (*alias)(t))
+	t.Extra = extra
+	return err
+}
+
+func (t As3Target) MarshalJSON() ([]byte, error) {
+	type alias As3Target
+	return marshalAs3((*alias)(&t), t.Extra)
+}
+
+func (c *As3Controls) UnmarshalJSON(data []byte) error {
+	type alias As3Controls
+	extra, err := unmarshalAs3(data, (*alias)(c))
+	c.Extra = extra
+	return err
+}
+
+func (c As3Controls) MarshalJSON() ([]byte, error) {
+	type alias As3Controls
+	return marshalAs3((*alias)(&c), c.Extra)
+}
+
+func (t *As3Tenant) UnmarshalJSON(data []byte) error {
+	type alias As3Tenant
+	extra, err := unmarshalAs3(data, (*alias)(t))
+	if err != nil {
+		return err
+	}
+	t.Applications = map[string]*As3Application{}
+	for k, raw := range extra {
+		if as3ClassOf(raw) != "Application" {
+			continue
+		}
+		var a As3Application
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return fmt.Errorf("application %s: %w", k, err)
+		}
+		t.Applications[k] = &a
+		delete(extra, k)
+	}
+	t.Extra = extra
+	return nil
+}
+
+func (t As3Tenant) MarshalJSON() ([]byte, error) {
+	type alias As3Tenant
+	all, err := withChildren(t.Extra, t.Applications)
+	if err != nil {
+		return nil, err
+	}
+	return marshalAs3((*alias)(&t), all)
+}
+
+func (a *As3Application) UnmarshalJSON(data []byte) error {
+	type alias As3Application
+	extra, err := unmarshalAs3(data, (*alias)(a))
+	if err != nil {
+		return err
+	}
+	a.Items = map[string]As3Item{}
+	for k, raw := range extra {
+		class := as3ClassOf(raw)
+		if class == "" {
+			continue
+		}
+		item, err := decodeAs3Item(class, raw)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", class, k, err)
+		}
+		a.Items[k] = item
+		delete(extra, k)
+	}
+	a.Extra = extra
+	return nil
+}
+
+func (a As3Application) MarshalJSON() ([]byte, error) {
+	type alias As3Application
+	all, err := withChildren(a.Extra, a.Items)
+	if err != nil {
+		return nil, err
+	}
+	return marshalAs3((*alias)(&a), all)
+}
+
+func decodeAs3Item(class string, data json.RawMessage) (As3Item, error) {
+	var item As3Item
+	switch class {
+	case "Service_HTTP", "Service_HTTPS":
+		item = &As3Service{}
+	case "Pool":
+		item = &As3Pool{}
+	case "Monitor":
+		item = &As3Monitor{}
+	case "TLS_Server":
+		item = &As3TLSServer{}
+	case "Certificate":
+		item = &As3Certificate{}
+	case "iRule":
+		item = &As3IRule{}
+	default:
+		item = &As3Object{}
+	}
+	if err := json.Unmarshal(data, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (p *As3Pointer) UnmarshalJSON(data []byte) error {
+	*p = As3Pointer{}
+	if err := json.Unmarshal(data, &p.Value); err == nil {
+		return nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err == nil && len(obj) == 1 {
+		if raw, ok := obj["use"]; ok && json.Unmarshal(raw, &p.Use) == nil {
+			return nil
+		}
+		if raw, ok := obj["bigip"]; ok && json.Unmarshal(raw, &p.BigIP) == nil {
+			return nil
+		}
+	}
+	*p = As3Pointer{Raw: append(json.RawMessage(nil), data...)}
+	return nil
+}
+
+func (p As3Pointer) MarshalJSON() ([]byte, error) {
+	switch {
+	case p.Raw != nil:
+		return p.Raw, nil
+	case p.Use != "":
+		return json.Marshal(map[string]string{"use": p.Use})
+	case p.BigIP != "":
+		return json.Marshal(map[string]string{"bigip": p.BigIP})
+	}
+	return json.Marshal(p.Value)
+}
+
+func (s *As3Service) UnmarshalJSON(data []byte) error {
+	type alias As3Service
+	extra, err := unmarshalAs3(data, (*alias)(s))
+	s.Extra = extra
+	return err
+}
+
+func (s As3Service) MarshalJSON() ([]byte, error) {
+	type alias As3Service
+	if s.Class == "" {
+		s.Class = "Service_HTTP"
+	}
+	return marshalAs3((*alias)(&s), s.Extra)
+}
+
+func (p *As3Pool) UnmarshalJSON(data []byte) error {
+	type alias As3Pool
+	extra, err := unmarshalAs3(data, (*alias)(p))
+	p.Extra = extra
+	return err
+}
+
+func (p As3Pool) MarshalJSON() ([]byte, error) {
+	type alias As3Pool
+	p.Class = p.As3Class()
+	return marshalAs3((*alias)(&p), p.Extra)
+}
+
+func (m *As3PoolMember) UnmarshalJSON(data []byte) error {
+	type alias As3PoolMember
+	extra, err := unmarshalAs3(data, (*alias)(m))
+	m.Extra = extra
+	return err
+}
+
+func (m As3PoolMember) MarshalJSON() ([]byte, error) {
+	type alias As3PoolMember
+	return marshalAs3((*alias)(&m), m.Extra)
+}
+
+func (m *As3Monitor) UnmarshalJSON(data []byte) error {
+	type alias As3Monitor
+	extra, err := unmarshalAs3(data, (*alias)(m))
+	m.Extra = extra
+	return err
+}
+
+func (m As3Monitor) MarshalJSON() ([]byte, error) {
+	type alias As3Monitor
+	m.Class = m.As3Class()
+	return marshalAs3((*alias)(&m), m.Extra)
+}
+
+func (t *As3TLSServer) UnmarshalJSON(data []byte) error {
+	type alias As3TLSServer
+	extra, err := unmarshalAs3(data, (*alias)(t))
+	t.Extra = extra
+	return err
+}
+
+func (t As3TLSServer) MarshalJSON() ([]byte, error) {
+	type alias As3TLSServer
+	t.Class = t.As3Class()
+	return marshalAs3((*alias)(&t), t.Extra)
+}
+
+func (c *As3TLSCertificate) UnmarshalJSON(data []byte) error {
+	type alias As3TLSCertificate
+	extra, err := unmarshalAs3(data, (*alias)(c))
+	c.Extra = extra
+	return err
+}
+
+func (c As3TLSCertificate) MarshalJSON() ([]byte, error) {
+	type alias As3TLSCertificate
+	return marshalAs3((*alias)(&c), c.Extra)
+}
+
+func (c *As3Certificate) UnmarshalJSON(data []byte) error {
+	type alias As3Certificate
+	extra, err := unmarshalAs3(data, (*alias)(c))
+	c.Extra = extra
+	return err
+}
+
+func (c As3Certificate) MarshalJSON() ([]byte, error) {
+	type alias As3Certificate
+	c.Class = c.As3Class()
+	return marshalAs3((*alias)(&c), c.Extra)
+}
+
+func (r *As3IRule) UnmarshalJSON(data []byte) error {
+	type alias As3IRule
+	extra, err := unmarshalAs3(data, (*alias)(r))
+	r.Extra = extra
+	return err
+}
+
+func (r As3IRule) MarshalJSON() ([]byte, error) {
+	type alias As3IRule
+	r.Class = r.As3Class()
+	return marshalAs3((*alias)(&r), r.Extra)
+}
+
+func (o *As3Object) UnmarshalJSON(data []byte) error {
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	o.Class = as3ClassOf(data)
+	delete(all, "class")
+	o.Properties = all
+	return nil
+}
+
+func (o As3Object) MarshalJSON() ([]byte, error) {
+	all := make(map[string]json.RawMessage, len(o.Properties)+1)
+	for k, raw := range o.Properties {
+		all[k] = raw
+	}
+	class, err := json.Marshal(o.Class)
+	if err != nil {
+		return nil, err
+	}
+	all["class"] = class
+	return json.Marshal(all)
+}
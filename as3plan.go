@@ -0,0 +1,252 @@
+package bigip
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// As3Added marks a tenant, application or property the declaration adds.
+	As3Added = "add"
+	// As3Removed marks a tenant, application or property the declaration
+	// removes.
+	As3Removed = "remove"
+	// As3Changed marks a property the declaration changes.
+	As3Changed = "change"
+)
+
+// as3InjectedFields are the properties of a declaration AS3 adds or
+// rewrites on its own, and that the plan ignores.
+var as3InjectedFields = []string{"updateMode", "controls", "id", "schemaVersion"}
+
+// As3Plan is what deploying a declaration would do.
+type As3Plan struct {
+	// DryRun is the outcome of the declaration as reported by AS3 for a
+	// "dry-run" action.
+	DryRun *As3Result `json:"dryRun"`
+	// Changes lists the differences between the declaration and the one
+	// deployed, sorted by path.
+	Changes []As3Change `json:"changes"`
+}
+
+// As3Change is a difference between a declaration and the one deployed.
+type As3Change struct {
+	// Action is As3Added, As3Removed or As3Changed.
+	Action string `json:"action"`
+	// Kind is "tenant", "application" or "property".
+	Kind string `json:"kind"`
+	// Path locates the change, such as "/Example/web/vs/virtualPort".
+	Path string `json:"path"`
+	// Before and After are the deployed and declared values, unset for
+	// additions and removals respectively.
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// HasChanges reports whether deploying the declaration would change
+// anything.
+func (p *As3Plan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// String renders the plan with one change per line, "+" for additions, "-"
+// for removals and "~" for changes.
+func (p *As3Plan) String() string {
+	var sb strings.Builder
+	for _, c := range p.Changes {
+		switch c.Action {
+		case As3Added:
+			fmt.Fprintf(&sb, "+ %s %s\n", c.Kind, c.Path)
+		case As3Removed:
+			fmt.Fprintf(&sb, "- %s %s\n", c.Kind, c.Path)
+		default:
+			before, _ := json.Marshal(c.Before)
+			after, _ := json.Marshal(c.After)
+			fmt.Fprintf(&sb, "~ %s %s: %s -> %s\n", c.Kind, c.Path, before, after)
+		}
+	}
+	return sb.String()
+}
+
+// PlanAs3 returns what deploying the AS3 declaration as3Json would do,
+// without changing the device: AS3 checks it with a dry-run, and it is
+// compared with the declaration deployed for its tenants, or for every
+// tenant when its updateMode is "complete". as3Json is either a request of
+// class AS3 or a bare declaration of class ADC.
+func (b *BigIP) PlanAs3(as3Json string) (*As3Plan, error) {
+	return b.PlanAs3Context(b.context(), as3Json)
+}
+
+// PlanAs3Context is the context-aware variant of PlanAs3.
+func (b *BigIP) PlanAs3Context(ctx context.Context, as3Json string) (*As3Plan, error) {
+	b = b.WithContext(ctx)
+	req, err := ParseAs3Request(as3Json)
+	if err != nil {
+		return nil, err
+	}
+	if req.Class == "ADC" {
+		// AS3 deploys a bare declaration, which has no action to set.
+		var decl As3Declaration
+		if err := json.Unmarshal([]byte(as3Json), &decl); err != nil {
+			return nil, fmt.Errorf("invalid AS3 declaration: %w", err)
+		}
+		req = &As3Request{Class: "AS3", Declaration: &decl}
+	}
+	if req.Declaration == nil {
+		return nil, fmt.Errorf("invalid AS3 declaration: no declaration")
+	}
+
+	dryRun := *req
+	dryRun.Action = "dry-run"
+	dryRunJson, err := dryRun.JSON()
+	if err != nil {
+		return nil, err
+	}
+	plan := &As3Plan{}
+	plan.DryRun, err = b.PostAs3Result(ctx, dryRunJson, "", "")
+	if err != nil {
+		return plan, err
+	}
+
+	// Tenants left out of a declaration in "complete" update mode are
+	// removed, so every deployed tenant matters then.
+	tenants := strings.Join(req.Declaration.TenantNames(), ",")
+	complete := req.Declaration.UpdateMode == "complete"
+	if complete {
+		tenants = ""
+	}
+	deployed := &As3Declaration{}
+	if tenants != "" || complete {
+		current, err := b.GetAs3(tenants, "", false)
+		if err != nil && !IsNotFound(err) {
+			return plan, err
+		}
+		if current != "" {
+			currentReq, err := ParseAs3Request(current)
+			if err != nil {
+				return plan, err
+			}
+			if currentReq.Declaration != nil {
+				deployed = currentReq.Declaration
+			}
+		}
+	}
+	plan.Changes, err = diffAs3Declarations(deployed, req.Declaration)
+	return plan, err
+}
+
+// diffAs3Declarations returns the changes from the declaration before to
+// after.
+func diffAs3Declarations(before, after *As3Declaration) ([]As3Change, error) {
+	from, err := as3Tree(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := as3Tree(after)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range []map[string]interface{}{from, to} {
+		delete(m, "class")
+		for _, field := range as3InjectedFields {
+			delete(m, field)
+		}
+	}
+
+	// Tenants declared without applications are removed.
+	var declared []string
+	for _, name := range after.TenantNames() {
+		if len(after.Tenants[name].Applications) == 0 {
+			delete(to, name)
+		} else {
+			declared = append(declared, name)
+		}
+	}
+	deployed := before.TenantNames()
+	added := splitList(tenantDifference(declared, deployed))
+	removed := splitList(tenantDifference(deployed, declared))
+	var changes []As3Change
+	for _, name := range added {
+		changes = append(changes, As3Change{Action: As3Added, Kind: "tenant", Path: "/" + name, After: to[name]})
+		delete(to, name)
+	}
+	for _, name := range removed {
+		// Tenants left out of the declaration are only removed in
+		// "complete" update mode.
+		if _, listed := after.Tenants[name]; listed || after.UpdateMode == "complete" {
+			changes = append(changes, As3Change{Action: As3Removed, Kind: "tenant", Path: "/" + name, Before: from[name]})
+		}
+		delete(from, name)
+	}
+	changes = append(changes, diffAs3Tree("", from, to)...)
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// as3Tree returns v as generic JSON values.
+func as3Tree(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	tree := map[string]interface{}{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func diffAs3Tree(path string, before, after map[string]interface{}) []As3Change {
+	keys := map[string]bool{}
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+	var changes []As3Change
+	for _, k := range sortedKeys(keys) {
+		p := path + "/" + k
+		from, inBefore := before[k]
+		to, inAfter := after[k]
+		switch {
+		case !inBefore:
+			changes = append(changes, As3Change{Action: As3Added, Kind: as3Kind(to), Path: p, After: to})
+		case !inAfter:
+			changes = append(changes, As3Change{Action: As3Removed, Kind: as3Kind(from), Path: p, Before: from})
+		case reflect.DeepEqual(from, to):
+		default:
+			fromObj, ok1 := from.(map[string]interface{})
+			toObj, ok2 := to.(map[string]interface{})
+			if ok1 && ok2 && fromObj["class"] == toObj["class"] {
+				changes = append(changes, diffAs3Tree(p, fromObj, toObj)...)
+			} else {
+				changes = append(changes, As3Change{Action: As3Changed, Kind: "property", Path: p, Before: from, After: to})
+			}
+		}
+	}
+	return changes
+}
+
+func as3Kind(v interface{}) string {
+	if obj, ok := v.(map[string]interface{}); ok {
+		switch obj["class"] {
+		case "Tenant":
+			return "tenant"
+		case "Application":
+			return "application"
+		}
+	}
+	return "property"
+}
+
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ",")
+}
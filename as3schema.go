@@ -0,0 +1,160 @@
+package bigip
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// as3CoreSchema is the schema used when no AS3 schema was supplied. It only
+// checks the structure every AS3 version agrees on: the request and ADC
+// classes, the class of tenants and applications, and the required
+// properties of the classes with a type in as3declaration.go.
+const as3CoreSchema = `{
+	"if": {"required": ["class"], "properties": {"class": {"const": "AS3"}}},
+	"then": {"$ref": "#/definitions/AS3"},
+	"else": {"$ref": "#/definitions/ADC"},
+	"definitions": {
+		"AS3": {
+			"type": "object",
+			"properties": {
+				"action": {"enum": ["deploy", "dry-run", "patch", "redeploy", "retrieve", "remove"]},
+				"persist": {"type": "boolean"},
+				"declaration": {"$ref": "#/definitions/ADC"}
+			},
+			"if": {"properties": {"action": {"enum": ["deploy", "dry-run"]}}},
+			"then": {"required": ["declaration"]}
+		},
+		"ADC": {
+			"type": "object",
+			"required": ["class", "schemaVersion"],
+			"properties": {
+				"class": {"const": "ADC"},
+				"schemaVersion": {"type": "string", "pattern": "^[0-9]+\\.[0-9]+\\.[0-9]+$"},
+				"id": {"type": "string"},
+				"label": {"type": "string"},
+				"remark": {"type": "string"},
+				"updateMode": {"enum": ["complete", "selective"]},
+				"target": {"type": "object"},
+				"controls": {"type": "object", "properties": {"class": {"const": "Controls"}}}
+			},
+			"additionalProperties": {"$ref": "#/definitions/child"}
+		},
+		"child": {
+			"if": {"type": "object"},
+			"then": {"required": ["class"], "properties": {"class": {"type": "string"}}, "allOf": [
+				{"if": {"required": ["class"], "properties": {"class": {"const": "Tenant"}}}, "then": {"$ref": "#/definitions/Tenant"}},
+				{"if": {"required": ["class"], "properties": {"class": {"const": "Application"}}}, "then": {"$ref": "#/definitions/Application"}},
+				{"if": {"required": ["class"], "properties": {"class": {"enum": ["Service_HTTP", "Service_HTTPS"]}}}, "then": {"$ref": "#/definitions/Service"}},
+				{"if": {"required": ["class"], "properties": {"class": {"const": "Pool"}}}, "then": {"$ref": "#/definitions/Pool"}},
+				{"if": {"required": ["class"], "properties": {"class": {"const": "Monitor"}}}, "then": {"required": ["monitorType"]}},
+				{"if": {"required": ["class"], "properties": {"class": {"const": "TLS_Server"}}}, "then": {"$ref": "#/definitions/TLS_Server"}},
+				{"if": {"required": ["class"], "properties": {"class": {"const": "Certificate"}}}, "then": {"required": ["certificate"]}},
+				{"if": {"required": ["class"], "properties": {"class": {"const": "iRule"}}}, "then": {"required": ["iRule"]}}
+			]}
+		},
+		"Tenant": {
+			"properties": {
+				"defaultRouteDomain": {"type": "integer", "minimum": 0, "maximum": 65534}
+			},
+			"additionalProperties": {"$ref": "#/definitions/child"}
+		},
+		"Application": {
+			"properties": {
+				"template": {"type": "string"}
+			},
+			"additionalProperties": {"$ref": "#/definitions/child"}
+		},
+		"Service": {
+			"required": ["virtualAddresses"],
+			"properties": {
+				"virtualAddresses": {"type": "array", "minItems": 1},
+				"virtualPort": {"type": "integer", "minimum": 0, "maximum": 65535},
+				"iRules": {"type": "array"}
+			}
+		},
+		"Pool": {
+			"properties": {
+				"monitors": {"type": "array"},
+				"members": {"type": "array", "items": {
+					"type": "object",
+					"required": ["servicePort"],
+					"properties": {
+						"servicePort": {"type": "integer", "minimum": 0, "maximum": 65535},
+						"serverAddresses": {"type": "array", "items": {"type": "string"}}
+					}
+				}}
+			}
+		},
+		"TLS_Server": {
+			"required": ["certificates"],
+			"properties": {
+				"certificates": {"type": "array", "minItems": 1, "items": {"type": "object", "required": ["certificate"]}}
+			}
+		}
+	}
+}`
+
+// As3Validator checks AS3 declarations against the AS3 JSON schema of their
+// schemaVersion, without network access. The custom formats of the AS3
+// schemas, such as "f5ip", are not checked; Unchecked lists them.
+type As3Validator struct {
+	schemaValidator
+}
+
+// NewAs3Validator returns a validator without AS3 schemas, which checks
+// declarations against a built-in schema of the structure all AS3 versions
+// share until schemas are added with AddSchema.
+func NewAs3Validator() *As3Validator {
+	return &As3Validator{newSchemaValidator("AS3", as3CoreSchema)}
+}
+
+// LoadAs3Validator returns a validator with the AS3 schemas in paths: files,
+// or directories of files, such as the "as3-schema-3.50.0-5.json" published
+// with each AS3 release. The version of each schema is taken from its file
+// name.
+func LoadAs3Validator(paths ...string) (*As3Validator, error) {
+	v := NewAs3Validator()
+	for _, path := range paths {
+		files := []string{path}
+		if info, err := os.Stat(path); err != nil {
+			return nil, err
+		} else if info.IsDir() {
+			if files, err = filepath.Glob(filepath.Join(path, "*.json")); err != nil {
+				return nil, err
+			}
+		}
+		for _, file := range files {
+			version := schemaVersionPattern.FindString(filepath.Base(file))
+			if version == "" {
+				return nil, fmt.Errorf("no AS3 version in the name of schema %s", file)
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			if err := v.AddSchema(version, data); err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+		}
+	}
+	return v, nil
+}
+
+// Validate checks as3Json, an AS3 request or an ADC declaration. It uses the
+// schema of the oldest AS3 version that supports the schemaVersion of the
+// declaration, or the built-in one when no schema was added; once schemas
+// are added, a missing or invalid schemaVersion is an error. The error is a
+// *SchemaError when the declaration does not match the schema.
+func (v *As3Validator) Validate(as3Json string) error {
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(as3Json), &doc); err != nil {
+		return fmt.Errorf("invalid AS3 declaration: %w", err)
+	}
+	declared, _ := doc["schemaVersion"].(string)
+	if decl, ok := doc["declaration"].(map[string]interface{}); ok && doc["class"] == "AS3" {
+		declared, _ = decl["schemaVersion"].(string)
+	}
+	return v.validate(declared, doc, "")
+}
@@ -0,0 +1,232 @@
+package bigip
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// as3PollInterval is the delay between two checks of an AS3 task.
+var as3PollInterval = 3 * time.Second
+
+// as3TaskTimeout bounds the wait for an AS3 task in the variants of the AS3
+// calls that take no context.
+var as3TaskTimeout = 30 * time.Minute
+
+// as3Context returns the context of b bounded by as3TaskTimeout, for the
+// AS3 calls that take no context.
+func (b *BigIP) as3Context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(b.context(), as3TaskTimeout)
+}
+
+// as3MaxResubmits bounds how many times a request is sent again after AS3
+// rejected it because another task was running.
+const as3MaxResubmits = 10
+
+// As3Result is the outcome of an AS3 task.
+type As3Result struct {
+	TaskID string
+	// Tenants holds the outcome for each tenant, in the order AS3 reported
+	// them.
+	Tenants []As3TenantResult
+}
+
+// As3TenantResult is the outcome of an AS3 task for a tenant.
+type As3TenantResult struct {
+	// Tenant is empty when the declaration was rejected as a whole, for
+	// instance as invalid.
+	Tenant  string `json:"tenant,omitempty"`
+	Code    int64  `json:"code"`
+	Message string `json:"message,omitempty"`
+	Host    string `json:"host,omitempty"`
+	// LineCount is the number of lines of configuration of the tenant.
+	LineCount int64 `json:"lineCount,omitempty"`
+	// RunTime is how long AS3 took to process the tenant, in milliseconds.
+	RunTime int64 `json:"runTime,omitempty"`
+	// Errors details why the declaration was rejected.
+	Errors []string `json:"errors,omitempty"`
+	// Response is the message of the device when the tenant failed to
+	// deploy.
+	Response string `json:"response,omitempty"`
+}
+
+// Succeeded reports whether the tenant was processed, changed or not.
+func (r *As3TenantResult) Succeeded() bool {
+	return r.Code >= 200 && r.Code < 300
+}
+
+// pending reports whether AS3 is still processing the tenant.
+func (r *As3TenantResult) pending() bool {
+	return r.Code == 0 || r.Message == "in progress" || r.Message == "pending"
+}
+
+func (r *As3TenantResult) String() string {
+	var sb strings.Builder
+	if r.Tenant != "" {
+		sb.WriteString(r.Tenant + ": ")
+	}
+	fmt.Fprintf(&sb, "%d %s", r.Code, r.Message)
+	if r.Response != "" && r.Response != r.Message {
+		sb.WriteString(": " + r.Response)
+	}
+	if len(r.Errors) > 0 {
+		sb.WriteString(" (" + strings.Join(r.Errors, "; ") + ")")
+	}
+	return sb.String()
+}
+
+// Succeeded returns the tenants that were processed.
+func (r *As3Result) Succeeded() []string {
+	var tenants []string
+	for i := range r.Tenants {
+		if r.Tenants[i].Succeeded() {
+			tenants = append(tenants, r.Tenants[i].Tenant)
+		}
+	}
+	return tenants
+}
+
+// Failed returns the tenants that failed to be processed.
+func (r *As3Result) Failed() []string {
+	var tenants []string
+	for i := range r.Tenants {
+		if !r.Tenants[i].Succeeded() && r.Tenants[i].Tenant != "" {
+			tenants = append(tenants, r.Tenants[i].Tenant)
+		}
+	}
+	return tenants
+}
+
+// Err returns nil if every tenant was processed, an *As3PartialSuccessError
+// if only some were, and an *As3Error otherwise.
+func (r *As3Result) Err() error {
+	succeeded := len(r.Succeeded())
+	switch {
+	case succeeded == len(r.Tenants):
+		return nil
+	case succeeded == 0:
+		return &As3Error{Result: r}
+	}
+	return &As3PartialSuccessError{Result: r}
+}
+
+func (r *As3Result) failures() string {
+	var failures []string
+	for i := range r.Tenants {
+		if !r.Tenants[i].Succeeded() {
+			failures = append(failures, r.Tenants[i].String())
+		}
+	}
+	return strings.Join(failures, ", ")
+}
+
+// As3Error reports an AS3 task that processed no tenant.
+type As3Error struct {
+	Result *As3Result
+}
+
+func (e *As3Error) Error() string {
+	return fmt.Sprintf("AS3 task %s failed: %s", e.Result.TaskID, e.Result.failures())
+}
+
+// As3PartialSuccessError reports an AS3 task that processed some tenants but
+// failed others. Result tells which.
+type As3PartialSuccessError struct {
+	Result *As3Result
+}
+
+func (e *As3PartialSuccessError) Error() string {
+	return fmt.Sprintf("AS3 task %s partially succeeded: processed %s, failed %s",
+		e.Result.TaskID, strings.Join(e.Result.Succeeded(), ","), e.Result.failures())
+}
+
+// as3Task is the body of mgmt/shared/appsvcs/task/<id>, and of the response
+// to a request sent with async=true.
+type as3Task struct {
+	ID      string            `json:"id"`
+	Results []As3TenantResult `json:"results"`
+}
+
+// runAs3Task sends an asynchronous AS3 request with submit, which returns the
+// body of the response, and waits for the task it started. The request is
+// sent again when AS3 answers that another task is running.
+func (b *BigIP) runAs3Task(ctx context.Context, submit func() ([]byte, error)) (*As3Result, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := submit()
+		if err != nil {
+			return nil, err
+		}
+		var task as3Task
+		if err := json.Unmarshal(resp, &task); err != nil {
+			return nil, fmt.Errorf("invalid AS3 response: %w", err)
+		}
+		if task.ID == "" {
+			return nil, fmt.Errorf("AS3 started no task: %s", strings.TrimSpace(string(resp)))
+		}
+		b.log().Debug("AS3 task started", "task_id", task.ID)
+
+		result, err := b.WaitForAs3Task(ctx, task.ID)
+		if result == nil || !result.busy() || attempt >= as3MaxResubmits {
+			return result, err
+		}
+		b.log().Debug("AS3 busy, waiting to send the request again", "task_id", task.ID)
+		if err := b.waitForAs3Idle(ctx); err != nil {
+			return result, err
+		}
+	}
+}
+
+// busy reports whether AS3 rejected the request because another task was
+// running.
+func (r *As3Result) busy() bool {
+	return len(r.Tenants) > 0 && r.Tenants[0].Code == http.StatusServiceUnavailable
+}
+
+// WaitForAs3Task polls the AS3 task id until it completes, and returns its
+// result. The error is nil if every tenant was processed, an
+// *As3PartialSuccessError if only some were, and an *As3Error otherwise.
+// Transport and server errors are retried, as AS3 may restart while it
+// processes a declaration, until ctx is done; other errors, such as an
+// unknown task, are returned at once.
+func (b *BigIP) WaitForAs3Task(ctx context.Context, id string) (*As3Result, error) {
+	client := b.WithContext(ctx)
+	var task as3Task
+	err := poll(ctx, as3PollInterval, func(context.Context) (bool, error) {
+		task = as3Task{}
+		err, _ := client.getForEntity(&task, uriMgmt, uriShared, uriAppsvcs, uriTask, id)
+		if err != nil {
+			return !isTransient(err), err
+		}
+		for i := range task.Results {
+			if task.Results[i].pending() {
+				return false, nil
+			}
+		}
+		return len(task.Results) > 0, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AS3 task %s: %w", id, err)
+	}
+	result := &As3Result{TaskID: id, Tenants: task.Results}
+	for i := range result.Tenants {
+		r := &result.Tenants[i]
+		if r.Succeeded() {
+			b.log().Debug("AS3 tenant processed", "task_id", id, "tenant", r.Tenant, "message", r.Message, "run_time_ms", r.RunTime)
+		} else {
+			b.log().Error("AS3 tenant failed", "task_id", id, "tenant", r.Tenant, "status", r.Code, "message", r.Message)
+		}
+	}
+	return result, result.Err()
+}
+
+// waitForAs3Idle waits until AS3 runs no task.
+func (b *BigIP) waitForAs3Idle(ctx context.Context) error {
+	client := b.WithContext(ctx)
+	return poll(ctx, as3PollInterval, func(context.Context) (bool, error) {
+		ids, err := client.getas3Taskid()
+		return err == nil && len(ids) == 0, err
+	})
+}
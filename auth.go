@@ -0,0 +1,209 @@
+package bigip
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	uriLoginPath  = "mgmt/shared/authn/login"
+	uriTokensPath = "mgmt/shared/authz/tokens/"
+)
+
+// tokenSession tracks the token acquired by NewTokenSession so it can be
+// refreshed before it lapses and re-acquired when the device answers 401.
+// It is shared by every copy of a client made through WithContext.
+type tokenSession struct {
+	loginReference string
+	timeout        time.Duration
+
+	// refreshMu serialises logins; mu guards the fields below it.
+	refreshMu sync.Mutex
+	mu        sync.RWMutex
+	token     string
+	expires   time.Time
+}
+
+func (s *tokenSession) current() (string, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.token, s.expires
+}
+
+func (s *tokenSession) set(token string, expires time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+	s.expires = expires
+}
+
+// isAuthURL reports whether path is one of the endpoints used to manage the
+// token itself, which must never trigger a refresh.
+func isAuthURL(path string) bool {
+	return strings.HasPrefix(path, uriLoginPath) || strings.HasPrefix(path, uriTokensPath)
+}
+
+// authToken returns the token to send with a request, if any.
+func (b *BigIP) authToken() string {
+	if b.tokens != nil {
+		token, _ := b.tokens.current()
+		return token
+	}
+	return b.Token
+}
+
+// CurrentToken returns the token the session currently authenticates with.
+// Unlike the Token field, which holds the token acquired by NewTokenSession,
+// it follows refreshes, and it is safe to call while requests run.
+func (b *BigIP) CurrentToken() string {
+	return b.authToken()
+}
+
+// login acquires a new token with the stored credentials and applies the
+// configured TokenTimeout to it.
+func (b *BigIP) login(ctx context.Context) error {
+	type authReq struct {
+		Username          string `json:"username"`
+		Password          string `json:"password"`
+		LoginProviderName string `json:"loginProviderName"`
+	}
+	type authResp struct {
+		Token struct {
+			Token string
+		}
+		Timeout struct {
+			Timeout int64
+		}
+		RefreshToken any `json:"refreshToken,omitempty"`
+	}
+
+	type timeoutReq struct {
+		Timeout int64 `json:"timeout"`
+	}
+
+	marshalJSONauth, err := json.Marshal(authReq{b.User, b.Password, b.tokens.loginReference})
+	if err != nil {
+		return err
+	}
+
+	req := &APIRequest{
+		Method:      "post",
+		URL:         uriLoginPath,
+		Body:        string(marshalJSONauth),
+		ContentType: "application/json",
+	}
+	resp, err := b.APICallContext(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if resp == nil {
+		return fmt.Errorf("unable to acquire authentication token")
+	}
+
+	var aresp authResp
+	err = json.Unmarshal(resp, &aresp)
+	if err != nil {
+		return err
+	}
+
+	if aresp.Token.Token == "" {
+		return fmt.Errorf("unable to acquire authentication token")
+	}
+
+	lifetime := time.Duration(aresp.Timeout.Timeout) * time.Second
+	b.tokens.set(aresp.Token.Token, time.Now().Add(lifetime))
+
+	//Once we have obtained a token, we should actually apply the configured timeout to it
+	if aresp.RefreshToken == nil && lifetime != b.tokens.timeout { // The inital value is the max timespan
+		marshalJSONtimeout, err := json.Marshal(timeoutReq{int64(b.tokens.timeout.Seconds())})
+		if err != nil {
+			return err
+		}
+
+		timeoutReq := &APIRequest{
+			Method:      "patch",
+			URL:         uriTokensPath + aresp.Token.Token,
+			Body:        string(marshalJSONtimeout),
+			ContentType: "application/json",
+		}
+		resp, err := b.APICallContext(ctx, timeoutReq)
+		if err != nil {
+			return err
+		}
+
+		if resp == nil {
+			return fmt.Errorf("unable to update token timeout")
+		}
+		var tresp struct {
+			Timeout int64 `json:"timeout"`
+		}
+		err = json.Unmarshal(resp, &tresp)
+		if err != nil {
+			return err
+		}
+		if time.Duration(tresp.Timeout)*time.Second != b.tokens.timeout {
+			return fmt.Errorf("failed to update token lifespan")
+		}
+		b.tokens.set(aresp.Token.Token, time.Now().Add(b.tokens.timeout))
+	}
+	return nil
+}
+
+// relogin acquires a new token unless another caller already replaced stale,
+// the token that was found to be expired or rejected.
+func (b *BigIP) relogin(ctx context.Context, stale string) error {
+	b.tokens.refreshMu.Lock()
+	defer b.tokens.refreshMu.Unlock()
+	if token, _ := b.tokens.current(); token != stale {
+		return nil
+	}
+	return b.login(ctx)
+}
+
+// refreshToken logs in again when the current token is close to expiring.
+// The token is replaced once less than a tenth of its lifetime remains.
+func (b *BigIP) refreshToken(ctx context.Context) error {
+	token, expires := b.tokens.current()
+	if expires.IsZero() || time.Until(expires) > b.tokens.timeout/10 {
+		return nil
+	}
+	return b.relogin(ctx, token)
+}
+
+// Logout deletes the session token on the device. It does nothing for
+// clients that authenticate with Basic Auth.
+func (b *BigIP) Logout() error {
+	if b.tokens == nil {
+		return nil
+	}
+	b.tokens.refreshMu.Lock()
+	defer b.tokens.refreshMu.Unlock()
+	token, _ := b.tokens.current()
+	if token == "" {
+		return nil
+	}
+	_, err := b.APICall(&APIRequest{
+		Method: "delete",
+		URL:    uriTokensPath + token,
+	})
+	if err != nil && !IsNotFound(err) && !IsUnauthorized(err) {
+		return err
+	}
+	b.tokens.set("", time.Time{})
+	return nil
+}
+
+// Close logs out of a token session and releases idle connections. The
+// client must not be used afterwards.
+func (b *BigIP) Close() error {
+	err := b.Logout()
+	if b.Transport != nil {
+		b.Transport.CloseIdleConnections()
+	}
+	return err
+}
@@ -1,9 +1,9 @@
 package bigip
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 )
@@ -415,7 +415,7 @@ func (b *BigIP) GetWafPolicyQuery(wafPolicyName string, partition string) (*WafP
 
 func (b *BigIP) GetWafPolicy(policyID string) (*WafPolicy, error) {
 	var wafPolicy WafPolicy
-	log.Printf("[DEBUG] WAF policy get with ID:%+v", policyID)
+	b.log().Debug("reading WAF policy", "policy_id", policyID)
 	err, _ := b.getForEntity(&wafPolicy, uriMgmt, uriTm, uriAsm, uriWafPol, policyID)
 	if err != nil {
 		return nil, err
@@ -431,7 +431,7 @@ func (b *BigIP) ExportPolicy(policyID string) (*PolicyStruct, error) {
 	exportPayload.Minimal = true
 	exportPayload.PolicyReference.Link = fmt.Sprintf("https://localhost/mgmt/tm/asm/policies/%s", policyID)
 
-	log.Printf("[INFO]payload:%+v", exportPayload)
+	b.log().Debug("exporting WAF policy", "policy_id", policyID, "payload", exportPayload)
 	resp, err := b.postReq(exportPayload, uriMgmt, uriTm, uriAsm, uriTasks, uriExportpolicy)
 	if err != nil {
 		return nil, err
@@ -464,7 +464,7 @@ func (b *BigIP) ExportPolicyFull(policyID string) (*string, error) {
 	exportPayload.Minimal = true
 	exportPayload.PolicyReference.Link = fmt.Sprintf("https://localhost/mgmt/tm/asm/policies/%s", policyID)
 
-	log.Printf("[INFO] payload:%+v", exportPayload)
+	b.log().Debug("exporting WAF policy", "policy_id", policyID, "payload", exportPayload)
 	resp, err := b.postReq(exportPayload, uriMgmt, uriTm, uriAsm, uriTasks, uriExportpolicy)
 	if err != nil {
 		return nil, err
@@ -483,14 +483,21 @@ func (b *BigIP) ExportPolicyFull(policyID string) (*string, error) {
 }
 
 func (b *BigIP) GetExportStatus(taskId string) (*ImportStatus, error) {
+	return b.GetExportStatusContext(b.context(), taskId)
+}
+
+// GetExportStatusContext is the context-aware variant of GetExportStatus. Task polling stops once ctx is done.
+func (b *BigIP) GetExportStatusContext(ctx context.Context, taskId string) (*ImportStatus, error) {
 	var exportStatus ImportStatus
-	err, _ := b.getForEntity(&exportStatus, uriMgmt, uriTm, uriAsm, uriTasks, uriExportpolicy, taskId)
+	err, _ := b.getForEntityContext(ctx, &exportStatus, uriMgmt, uriTm, uriAsm, uriTasks, uriExportpolicy, taskId)
 	if err != nil {
 		return nil, err
 	}
 	if exportStatus.Status != "COMPLETED" && exportStatus.Status != "FAILURE" {
-		time.Sleep(5 * time.Second)
-		return b.GetExportStatus(taskId)
+		if err := sleepContext(ctx, 5*time.Second); err != nil {
+			return nil, err
+		}
+		return b.GetExportStatusContext(ctx, taskId)
 		//return nil
 	}
 	if exportStatus.Status == "FAILURE" {
@@ -521,8 +528,13 @@ func (b *BigIP) GetWafPolicyParameters(policyID string) (*Parameters, error) {
 }
 
 func (b *BigIP) GetImportStatus(taskId string) error {
+	return b.GetImportStatusContext(b.context(), taskId)
+}
+
+// GetImportStatusContext is the context-aware variant of GetImportStatus. Task polling stops once ctx is done.
+func (b *BigIP) GetImportStatusContext(ctx context.Context, taskId string) error {
 	var importStatus ImportStatus
-	err, _ := b.getForEntity(&importStatus, uriMgmt, uriTm, uriAsm, uriTasks, uriImportpolicy, taskId)
+	err, _ := b.getForEntityContext(ctx, &importStatus, uriMgmt, uriTm, uriAsm, uriTasks, uriImportpolicy, taskId)
 	if err != nil {
 		return err
 	}
@@ -533,15 +545,22 @@ func (b *BigIP) GetImportStatus(taskId string) error {
 		return fmt.Errorf("[ERROR] WafPolicy import failed with :%+v", importStatus.Result)
 	}
 	if importStatus.Status == "STARTED" {
-		time.Sleep(5 * time.Second)
-		return b.GetImportStatus(taskId)
+		if err := sleepContext(ctx, 5*time.Second); err != nil {
+			return err
+		}
+		return b.GetImportStatusContext(ctx, taskId)
 	}
 	return nil
 }
 
 func (b *BigIP) GetApplyStatus(taskId string) error {
+	return b.GetApplyStatusContext(b.context(), taskId)
+}
+
+// GetApplyStatusContext is the context-aware variant of GetApplyStatus. Task polling stops once ctx is done.
+func (b *BigIP) GetApplyStatusContext(ctx context.Context, taskId string) error {
 	var applyStatus ApplyStatus
-	err, _ := b.getForEntity(&applyStatus, uriMgmt, uriTm, uriAsm, uriTasks, uriApplypolicy, taskId)
+	err, _ := b.getForEntityContext(ctx, &applyStatus, uriMgmt, uriTm, uriAsm, uriTasks, uriApplypolicy, taskId)
 	if err != nil {
 		return err
 	}
@@ -552,8 +571,10 @@ func (b *BigIP) GetApplyStatus(taskId string) error {
 		return fmt.Errorf("[ERROR] WafPolicy Apply failed with :%+v", applyStatus.Result.Message)
 	}
 	if applyStatus.Status == "STARTED" {
-		time.Sleep(5 * time.Second)
-		return b.GetApplyStatus(taskId)
+		if err := sleepContext(ctx, 5*time.Second); err != nil {
+			return err
+		}
+		return b.GetApplyStatusContext(ctx, taskId)
 	}
 	return nil
 }
@@ -598,7 +619,7 @@ func (b *BigIP) ImportAwafJson(awafPolicyName, awafJsonContent, policyID string)
 			//FullPath: awafPolicyName,
 			PolicyReference: policyPath,
 		}
-		log.Printf("[DEBUG] Import policy:%+v", policy)
+		b.log().Debug("importing WAF policy", "policy", awafPolicyName, "payload", policy)
 		resp, err := b.postReq(policy, uriMgmt, uriTm, uriAsm, uriTasks, uriImportpolicy)
 		if err != nil {
 			return "", err
@@ -610,7 +631,7 @@ func (b *BigIP) ImportAwafJson(awafPolicyName, awafJsonContent, policyID string)
 		}
 		return taskStatus.ID, nil
 	}
-	log.Printf("[DEBUG] Import policy:%+v", applywaf)
+	b.log().Debug("importing WAF policy", "policy", awafPolicyName, "payload", applywaf)
 	resp, err := b.postReq(applywaf, uriMgmt, uriTm, uriAsm, uriTasks, uriImportpolicy)
 	if err != nil {
 		return "", err
@@ -646,7 +667,7 @@ func (b *BigIP) ApplyAwafJson(awafPolicyName, policyID string) (string, error) {
 		}{
 			PolicyReference: policyPath,
 		}
-		log.Printf("import policy:%+v", policy)
+		b.log().Debug("applying WAF policy", "policy", awafPolicyName, "payload", policy)
 		resp, err := b.postReq(policy, uriMgmt, uriTm, uriAsm, uriTasks, uriApplypolicy)
 		if err != nil {
 			return "", err
@@ -659,7 +680,7 @@ func (b *BigIP) ApplyAwafJson(awafPolicyName, policyID string) (string, error) {
 		return taskStatus.ID, nil
 	}
 
-	log.Printf("apply policy body:%+v", applywaf)
+	b.log().Debug("applying WAF policy", "policy", awafPolicyName, "payload", applywaf)
 	resp, err := b.postReq(applywaf, uriMgmt, uriTm, uriAsm, uriTasks, uriApplypolicy)
 	if err != nil {
 		return "", err
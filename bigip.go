@@ -13,17 +13,16 @@ package bigip
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
-	"net/url"
-	"os"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -37,33 +36,102 @@ var defaultConfigOptions = &ConfigOptions{
 type ConfigOptions struct {
 	APICallTimeout time.Duration
 	TokenTimeout   time.Duration
+	// APICallRetries is the total number of attempts made for a call when
+	// RetryPolicy is not set.
 	APICallRetries int
+	// RetryPolicy decides which failed calls are retried and when. Defaults to
+	// an ExponentialBackoff bounded by APICallRetries.
+	RetryPolicy RetryPolicy
 }
 
 type Config struct {
-	Address            string
-	Port               string
-	Username           string
-	Password           string
-	Token              string
-	CertVerifyDisable  bool
-	TrustedCertificate string
-	LoginReference     string `json:"loginProviderName"`
-	ConfigOptions      *ConfigOptions
+	Address           string
+	Port              string
+	Username          string
+	Password          string
+	Token             string
+	CertVerifyDisable bool
+	// TrustedCertificate is the path of a PEM bundle of CA certificates to
+	// trust in addition to the system pool. TrustedCertificatePEM holds the
+	// same as bytes; both may be set.
+	TrustedCertificate    string
+	TrustedCertificatePEM []byte
+	// ClientCertificate and ClientKey are the paths of a PEM certificate and
+	// key presented for mutual TLS. ClientCertificatePEM and ClientKeyPEM
+	// hold the same as bytes. The key may be bundled with the certificate.
+	ClientCertificate    string
+	ClientKey            string
+	ClientCertificatePEM []byte
+	ClientKeyPEM         []byte
+	// CertificateFingerprint pins the SHA-256 fingerprint of the device
+	// certificate, in hex with or without colons. A pinned certificate is
+	// trusted even if self-signed; it is still verified against the trusted
+	// CAs when some are configured.
+	CertificateFingerprint string
+	// MinTLSVersion is the minimum TLS version accepted, such as
+	// tls.VersionTLS12. Zero selects the crypto/tls default.
+	MinTLSVersion uint16
+
+	LoginReference string `json:"loginProviderName"`
+	ConfigOptions  *ConfigOptions
+	// Interceptors wrap every HTTP round trip made by the client, including
+	// upload chunks and AS3/FAST task polling. The first one is the outermost.
+	Interceptors []Interceptor
+	// Logger receives the client's debug and diagnostic output. Nothing is
+	// logged when it is nil.
+	Logger *slog.Logger
 }
 
 // BigIP is a container for our session state.
+//
+// A *BigIP returned by NewSession or NewTokenSession is safe for concurrent
+// use by multiple goroutines. Its exported fields must not be modified once
+// requests are in flight; derive a new client with WithContext instead. The
+// exception is the transaction started by StartTransaction, which every
+// request of the session joins, from any goroutine, until it is committed:
+// use BeginTransaction to confine a transaction to a client of its own.
 type BigIP struct {
-	Host      string
-	User      string
-	Password  string
-	Token     string // if set, will be used instead of User/Password
+	Host     string
+	User     string
+	Password string
+	// Token, if set, is used instead of User/Password. NewTokenSession
+	// stores the token it acquires here, and leaves the field alone when
+	// it refreshes the token: use CurrentToken for the token in use.
+	Token     string
 	Transport *http.Transport
 	// UserAgent is an optional field that specifies the caller of this request.
-	UserAgent     string
-	Teem          bool
+	UserAgent string
+	Teem      bool
+	// As3Validator, if set, checks AS3 declarations before PostAs3Bigip
+	// sends them, so that invalid ones fail without reaching the device.
+	As3Validator *As3Validator
+	// DoValidator, if set, checks DO declarations before PostDo sends them.
+	DoValidator   *DoValidator
 	ConfigOptions *ConfigOptions
-	Transaction   string
+	// Transaction, if set, stamps every request with this transaction ID.
+	// StartTransaction sets it and CommitTransaction clears it; the
+	// transaction they manage is also joined by the clients derived from b
+	// with WithContext.
+	Transaction string
+
+	// client is built once by NewSession and shared by every request.
+	client *http.Client
+	// state holds the mutable session state shared with WithContext copies.
+	state *sessionState
+	// ctx, when set through WithContext, bounds every request issued by this client.
+	ctx context.Context
+	// tokens is set by NewTokenSession and keeps the token fresh.
+	tokens *tokenSession
+	// interceptors wrap every HTTP round trip, see Config.Interceptors.
+	interceptors []Interceptor
+	// tx is the transaction requests are queued in, for the client returned
+	// by Tx.Client.
+	tx string
+	// logger is set from Config.Logger, see log.
+	logger *slog.Logger
+	// configErr is set when NewSession could not apply the Config, such as
+	// an unreadable certificate; it is returned by every request.
+	configErr error
 }
 
 // APIRequest builds our request before sending it to the server.
@@ -72,6 +140,10 @@ type APIRequest struct {
 	URL         string
 	Body        string
 	ContentType string
+
+	// idempotent marks a request that can be replayed after a transport
+	// error whatever its method, such as an upload chunk.
+	idempotent bool
 }
 
 // Upload contains information about a file upload status
@@ -85,7 +157,8 @@ type Upload struct {
 	LastUpdateMicros   int            `json:"lastUpdateMicros"`
 }
 
-// RequestError contains information about any error we get from a request.
+// RequestError is the error body iControl REST sends with a failed request.
+// APICall surfaces it as an *APIError.
 type RequestError struct {
 	Code       int      `json:"code,omitempty"`
 	Message    string   `json:"message,omitempty"`
@@ -107,7 +180,9 @@ func (r *RequestError) Error() error {
 	return nil
 }
 
-// NewSession sets up our connection to the BIG-IP system.
+// NewSession sets up our connection to the BIG-IP system. If the TLS
+// settings in bigipConfig cannot be applied, every request made with the
+// returned client fails with the reason; use OpenSession to get it up front.
 // func NewSession(host, port, user, passwd string, configOptions *ConfigOptions) *BigIP {
 func NewSession(bigipConfig *Config) *BigIP {
 	var urlString string
@@ -120,146 +195,130 @@ func NewSession(bigipConfig *Config) *BigIP {
 		urlString = urlString + ":" + bigipConfig.Port
 	}
 	if bigipConfig.ConfigOptions == nil {
-		bigipConfig.ConfigOptions = defaultConfigOptions
+		configOptions := *defaultConfigOptions
+		bigipConfig.ConfigOptions = &configOptions
+	}
+	tlsConfig, err := newTLSConfig(bigipConfig)
+	if err != nil {
+		// Every request made with the client fails with err.
+		tlsConfig = &tls.Config{}
+	}
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
 	}
 	return &BigIP{
-		Host:     urlString,
-		User:     bigipConfig.Username,
-		Password: bigipConfig.Password,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: bigipConfig.CertVerifyDisable,
-			},
+		Host:      urlString,
+		User:      bigipConfig.Username,
+		Password:  bigipConfig.Password,
+		Transport: transport,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   bigipConfig.ConfigOptions.APICallTimeout,
 		},
+		state:         &sessionState{},
 		ConfigOptions: bigipConfig.ConfigOptions,
+		interceptors:  bigipConfig.Interceptors,
+		logger:        bigipConfig.Logger,
+		configErr:     err,
 	}
 }
 
-// NewTokenSession sets up our connection to the BIG-IP system, and
-// instructs the session to use token authentication instead of Basic
-// Auth. This is required when using an external authentication
-// provider, such as Radius or Active Directory. loginProviderName is
-// probably "tmos" but your environment may vary.
-func NewTokenSession(bigipConfig *Config) (b *BigIP, err error) {
-	type authReq struct {
-		Username          string `json:"username"`
-		Password          string `json:"password"`
-		LoginProviderName string `json:"loginProviderName"`
-	}
-	type authResp struct {
-		Token struct {
-			Token string
-		}
-		Timeout struct {
-			Timeout int64
-		}
-		RefreshToken any `json:"refreshToken,omitempty"`
+// OpenSession is NewSession, but it returns an error when bigipConfig cannot
+// be applied, such as an unreadable CA bundle or an invalid certificate
+// fingerprint, instead of failing every request with it.
+func OpenSession(bigipConfig *Config) (*BigIP, error) {
+	b := NewSession(bigipConfig)
+	if b.configErr != nil {
+		return nil, b.configErr
 	}
+	return b, nil
+}
 
-	type timeoutReq struct {
-		Timeout int64 `json:"timeout"`
-	}
+// sessionState is the mutable state of a session. It is shared by a client
+// and every copy made of it with WithContext.
+type sessionState struct {
+	// mu guards the fields below, and the Transaction field of the clients
+	// sharing the state.
+	mu          sync.RWMutex
+	transaction string
+	// started is the last transaction started by StartTransaction, which
+	// copies of the client may still hold in their Transaction field.
+	started string
+}
 
-	if bigipConfig.LoginReference == "" {
-		bigipConfig.LoginReference = "tmos"
+// httpClient returns the client built by NewSession, or a new one when the
+// Transport or the APICallTimeout was changed since, or for a BigIP
+// assembled by hand.
+func (b *BigIP) httpClient() *http.Client {
+	var timeout time.Duration
+	if b.ConfigOptions != nil {
+		timeout = b.ConfigOptions.APICallTimeout
 	}
-	auth := authReq{
-		bigipConfig.Username,
-		bigipConfig.Password,
-		bigipConfig.LoginReference,
+	if b.client != nil && b.client.Transport == b.Transport && b.client.Timeout == timeout {
+		return b.client
 	}
-
-	marshalJSONauth, err := json.Marshal(auth)
-	if err != nil {
-		return
+	return &http.Client{
+		Transport: b.Transport,
+		Timeout:   timeout,
 	}
+}
 
-	req := &APIRequest{
-		Method:      "post",
-		URL:         "mgmt/shared/authn/login",
-		Body:        string(marshalJSONauth),
-		ContentType: "application/json",
-	}
-
-	b = NewSession(bigipConfig)
-	if !bigipConfig.CertVerifyDisable {
-		rootCAs, _ := x509.SystemCertPool()
-		if rootCAs == nil {
-			rootCAs = x509.NewCertPool()
-		}
-		certPEM, err := os.ReadFile(bigipConfig.TrustedCertificate)
-		if err != nil {
-			return b, fmt.Errorf("provide Valid Trusted certificate path :%+v", err)
-			// log.Printf("[DEBUG]read cert PEM/crt file error:%+v", err)
-		}
-		// TODO: Make sure appMgr sets certificates in bigipInfo
-		// certs := certPEM)
-
-		// Append our certs to the system pool
-		if ok := rootCAs.AppendCertsFromPEM(certPEM); !ok {
-			fmt.Println("[DEBUG] No certs appended, using only system certs")
+// transactionID returns the transaction requests should be stamped with.
+func (b *BigIP) transactionID() string {
+	if b.state == nil {
+		if b.Transaction != "" {
+			return b.Transaction
 		}
-		b.Transport.TLSClientConfig.RootCAs = rootCAs
+		return b.tx
 	}
-	resp, err := b.APICall(req)
-	if err != nil {
-		return
+	b.state.mu.RLock()
+	defer b.state.mu.RUnlock()
+	if b.Transaction != "" && b.Transaction != b.state.started {
+		return b.Transaction
 	}
-
-	if resp == nil {
-		err = fmt.Errorf("unable to acquire authentication token")
-		return
+	if b.tx != "" {
+		return b.tx
 	}
+	return b.state.transaction
+}
 
-	var aresp authResp
-	err = json.Unmarshal(resp, &aresp)
-	if err != nil {
+// setTransactionID records id as the transaction started by
+// StartTransaction, or its end when id is empty.
+func (b *BigIP) setTransactionID(id string) {
+	if b.state == nil {
+		b.Transaction = id
 		return
 	}
-
-	if aresp.Token.Token == "" {
-		err = fmt.Errorf("unable to acquire authentication token")
-		return
+	b.state.mu.Lock()
+	defer b.state.mu.Unlock()
+	b.state.transaction = id
+	if id != "" {
+		b.state.started = id
 	}
+	b.Transaction = id
+}
 
-	b.Token = aresp.Token.Token
-
-	//Once we have obtained a token, we should actually apply the configured timeout to it
-	if aresp.RefreshToken == nil && time.Duration(aresp.Timeout.Timeout)*time.Second != bigipConfig.ConfigOptions.TokenTimeout { // The inital value is the max timespan
-		timeout := timeoutReq{
-			int64(bigipConfig.ConfigOptions.TokenTimeout.Seconds()),
-		}
-
-		marshalJSONtimeout, errToken := json.Marshal(timeout)
-		if errToken != nil {
-			return b, errToken
-		}
-
-		timeoutReq := &APIRequest{
-			Method:      "patch",
-			URL:         ("mgmt/shared/authz/tokens/" + b.Token),
-			Body:        string(marshalJSONtimeout),
-			ContentType: "application/json",
-		}
-		resp, errToken := b.APICall(timeoutReq)
-		if errToken != nil {
-			return b, errToken
-		}
+// NewTokenSession sets up our connection to the BIG-IP system, and
+// instructs the session to use token authentication instead of Basic
+// Auth. This is required when using an external authentication
+// provider, such as Radius or Active Directory. loginProviderName is
+// probably "tmos" but your environment may vary.
+func NewTokenSession(bigipConfig *Config) (b *BigIP, err error) {
+	if bigipConfig.LoginReference == "" {
+		bigipConfig.LoginReference = "tmos"
+	}
 
-		if resp == nil {
-			errToken = fmt.Errorf("unable to update token timeout")
-			return b, errToken
-		}
-		var tresp map[string]interface{}
-		errToken = json.Unmarshal(resp, &tresp)
-		if errToken != nil {
-			return b, errToken
-		}
-		if time.Duration(int64(tresp["timeout"].(float64)))*time.Second != bigipConfig.ConfigOptions.TokenTimeout {
-			err = fmt.Errorf("failed to update token lifespan")
-			return
-		}
+	b = NewSession(bigipConfig)
+	if b.configErr != nil {
+		return b, b.configErr
+	}
+	b.tokens = &tokenSession{
+		loginReference: bigipConfig.LoginReference,
+		timeout:        bigipConfig.ConfigOptions.TokenTimeout,
 	}
+	err = b.login(b.context())
+	b.Token, _ = b.tokens.current()
 	return
 }
 
@@ -275,9 +334,85 @@ func (client *BigIP) ValidateConnection() error {
 	return nil
 }
 
+// WithContext returns a shallow copy of b whose API calls, retries and task
+// polling are bound to ctx. The original client is left untouched, so the
+// copy can be handed to a single unit of work and cancelled independently.
+func (b *BigIP) WithContext(ctx context.Context) *BigIP {
+	if ctx == nil {
+		panic("nil context")
+	}
+	if b.state != nil {
+		// StartTransaction may be writing the Transaction field.
+		b.state.mu.RLock()
+		defer b.state.mu.RUnlock()
+	}
+	b2 := *b
+	b2.ctx = ctx
+	return &b2
+}
+
+// context returns the context bound through WithContext, or context.Background().
+func (b *BigIP) context() context.Context {
+	if b.ctx != nil {
+		return b.ctx
+	}
+	return context.Background()
+}
+
+// sleepContext pauses for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// poll calls check every interval until it reports done, returning the error
+// check returned with it, or until ctx is done. Errors returned while not
+// done are taken as transient, such as while the device restarts, and the
+// last one is reported if ctx ends first.
+func poll(ctx context.Context, interval time.Duration, check func(ctx context.Context) (done bool, err error)) error {
+	var lastErr error
+	for {
+		done, err := check(ctx)
+		if done {
+			return err
+		}
+		if err != nil {
+			lastErr = err
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			if lastErr != nil {
+				return fmt.Errorf("%w (last error: %v)", err, lastErr)
+			}
+			return err
+		}
+	}
+}
+
+// isTransient reports whether err may go away when the request is made
+// again later: a transport error or a server error (5xx).
+func isTransient(err error) bool {
+	if apiErr, ok := asAPIError(err); ok {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	return err != nil
+}
+
 // APICall is used to query the BIG-IP web API.
 func (b *BigIP) APICall(options *APIRequest) ([]byte, error) {
-	var req *http.Request
+	return b.APICallContext(b.context(), options)
+}
+
+// APICallContext is used to query the BIG-IP web API. The request, and any
+// retries of it, are abandoned once ctx is cancelled or its deadline expires.
+// With a token session the token is refreshed before it lapses, and a request
+// rejected with 401 is replayed once after logging in again.
+func (b *BigIP) APICallContext(ctx context.Context, options *APIRequest) ([]byte, error) {
 	var format string
 	if strings.Contains(options.URL, "mgmt/") {
 		format = "%s/%s"
@@ -285,65 +420,107 @@ func (b *BigIP) APICall(options *APIRequest) ([]byte, error) {
 		format = "%s/mgmt/tm/%s"
 	}
 	urlString := fmt.Sprintf(format, b.Host, options.URL)
-	maxRetries := b.ConfigOptions.APICallRetries
-	for i := 0; i < maxRetries; i++ {
-		body := bytes.NewReader([]byte(options.Body))
-		var err error
-		req, err = http.NewRequest(strings.ToUpper(options.Method), urlString, body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	res, err := b.doAuthenticated(ctx, options, urlString, []byte(options.Body), nil)
+	if res == nil {
+		return nil, err
+	}
+	return res.Body, err
+}
+
+// doAuthenticated is do for a token session: the token is refreshed before
+// it lapses, and a request rejected with 401 is replayed once after logging
+// in again. It is do itself for other clients.
+func (b *BigIP) doAuthenticated(ctx context.Context, options *APIRequest, urlString string, body []byte, extra http.Header) (*CallResponse, error) {
+	if b.tokens == nil || isAuthURL(options.URL) {
+		return b.do(ctx, options, urlString, body, extra)
+	}
+	if err := b.refreshToken(ctx); err != nil {
+		return nil, fmt.Errorf("failed to refresh authentication token: %w", err)
+	}
+	token := b.authToken()
+	res, err := b.do(ctx, options, urlString, body, extra)
+	if IsUnauthorized(err) {
+		if loginErr := b.relogin(ctx, token); loginErr != nil {
+			return res, err
 		}
-		b.Transport.Proxy = func(reqNew *http.Request) (*url.URL, error) {
-			return http.ProxyFromEnvironment(reqNew)
+		return b.do(ctx, options, urlString, body, extra)
+	}
+	return res, err
+}
+
+// do sends body to urlString on behalf of options, with the extra header,
+// retrying according to the RetryPolicy. When the device answers with a
+// failure status, the response is returned along with the *APIError.
+func (b *BigIP) do(ctx context.Context, options *APIRequest, urlString string, body []byte, extra http.Header) (*CallResponse, error) {
+	method := strings.ToUpper(options.Method)
+	if b.configErr != nil {
+		return nil, b.configErr
+	}
+	policy := b.retryPolicy()
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-		client := &http.Client{
-			Transport: b.Transport,
-			Timeout:   b.ConfigOptions.APICallTimeout,
+		req, err := http.NewRequestWithContext(ctx, method, urlString, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 		}
-		if b.Token != "" {
-			req.Header.Set("X-F5-Auth-Token", b.Token)
-		} else if options.URL != "mgmt/shared/authn/login" {
-			req.SetBasicAuth(b.User, b.Password)
+		if options.URL != uriLoginPath {
+			if token := b.authToken(); token != "" {
+				req.Header.Set("X-F5-Auth-Token", token)
+			} else {
+				req.SetBasicAuth(b.User, b.Password)
+			}
 		}
 
-		if len(b.Transaction) > 0 {
-			req.Header.Set("X-F5-REST-Coordination-Id", b.Transaction)
+		if tx := b.transactionID(); tx != "" && !isTransactionURL(options.URL) {
+			req.Header.Set("X-F5-REST-Coordination-Id", tx)
 		}
 
 		if len(options.ContentType) > 0 {
 			req.Header.Set("Content-Type", options.ContentType)
 		}
-		res, err := client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer res.Body.Close()
-		data, _ := io.ReadAll(res.Body)
-		contentType := ""
-		if ctHeaders, ok := res.Header["Content-Type"]; ok && len(ctHeaders) > 0 {
-			contentType = ctHeaders[0]
-		}
-		if res.StatusCode >= 400 {
-			if strings.Contains(contentType, "application/json") {
-				var reqError RequestError
-				err = json.Unmarshal(data, &reqError)
-				if err != nil {
-					return nil, err
-				}
-				// With how some of the requests come back from AS3, we sometimes have a nested error, so check the entire message for the "active asynchronous task" error
-				if res.StatusCode == 503 || reqError.Code == 503 || strings.Contains(strings.ToLower(reqError.Message), strings.ToLower("there is an active asynchronous task executing")) {
-					time.Sleep(10 * time.Second)
-					continue
-				}
-				return data, b.checkError(data)
-			} else {
-				return data, fmt.Errorf("HTTP %d :: %s", res.StatusCode, string(data[:]))
+		for k, v := range extra {
+			req.Header[k] = v
+		}
+		var statusCode int
+		var header http.Header
+		res, err := b.roundTrip(&Call{APIRequest: options, Request: req, Attempt: attempt})
+		if err == nil {
+			statusCode, header = res.StatusCode, res.Header
+			b.log().LogAttrs(ctx, slog.LevelDebug, "API call",
+				slog.String("method", method),
+				slog.String("path", options.URL),
+				slog.Int("status", statusCode),
+				slog.Int("attempt", attempt),
+				slog.Duration("duration", res.Duration))
+			if statusCode < 400 {
+				return res, nil
 			}
-			//return data, errors.New(fmt.Sprintf("HTTP %d :: %s", res.StatusCode, string(data[:])))
+			err = newAPIError(method, urlString, res)
+		}
+		delay, retry := policy.ShouldRetry(&RetryAttempt{
+			Attempt:    attempt,
+			Method:     method,
+			Idempotent: options.idempotent,
+			StatusCode: statusCode,
+			Err:        err,
+			RetryAfter: parseRetryAfter(header),
+		})
+		if !retry {
+			return res, err
+		}
+		b.log().LogAttrs(ctx, slog.LevelWarn, "retrying API call",
+			slog.String("method", method),
+			slog.String("path", options.URL),
+			slog.Int("status", statusCode),
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay),
+			slog.Any("error", err))
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
 		}
-		return data, nil
 	}
-	return nil, fmt.Errorf("service unavailable after %d attempts", maxRetries)
 }
 
 func (b *BigIP) iControlPath(parts []string) string {
@@ -359,12 +536,17 @@ func (b *BigIP) iControlPath(parts []string) string {
 
 // Generic delete
 func (b *BigIP) delete(path ...string) error {
+	return b.deleteContext(b.context(), path...)
+}
+
+// deleteContext is the context-aware variant of delete.
+func (b *BigIP) deleteContext(ctx context.Context, path ...string) error {
 	req := &APIRequest{
 		Method: "delete",
 		URL:    b.iControlPath(path),
 	}
 
-	_, callErr := b.APICall(req)
+	_, callErr := b.APICallContext(ctx, req)
 	return callErr
 }
 
@@ -397,6 +579,11 @@ func (b *BigIP) deleteReqBody(body interface{}, path ...string) ([]byte, error)
 }
 
 func (b *BigIP) post(body interface{}, path ...string) error {
+	return b.postContext(b.context(), body, path...)
+}
+
+// postContext is the context-aware variant of post.
+func (b *BigIP) postContext(ctx context.Context, body interface{}, path ...string) error {
 	marshalJSON, err := jsonMarshal(body)
 	if err != nil {
 		return err
@@ -409,7 +596,7 @@ func (b *BigIP) post(body interface{}, path ...string) error {
 		ContentType: "application/json",
 	}
 
-	_, callErr := b.APICall(req)
+	_, callErr := b.APICallContext(ctx, req)
 	return callErr
 }
 
@@ -442,6 +629,11 @@ func (b *BigIP) postAS3Req(body interface{}, path ...string) ([]byte, error) {
 }
 
 func (b *BigIP) put(body interface{}, path ...string) error {
+	return b.putContext(b.context(), body, path...)
+}
+
+// putContext is the context-aware variant of put.
+func (b *BigIP) putContext(ctx context.Context, body interface{}, path ...string) error {
 	marshalJSON, err := jsonMarshal(body)
 	if err != nil {
 		return err
@@ -454,7 +646,7 @@ func (b *BigIP) put(body interface{}, path ...string) error {
 		ContentType: "application/json",
 	}
 
-	_, callErr := b.APICall(req)
+	_, callErr := b.APICallContext(ctx, req)
 	return callErr
 }
 
@@ -476,6 +668,11 @@ func (b *BigIP) putReq(body interface{}, path ...string) ([]byte, error) {
 }
 
 func (b *BigIP) patch(body interface{}, path ...string) error {
+	return b.patchContext(b.context(), body, path...)
+}
+
+// patchContext is the context-aware variant of patch.
+func (b *BigIP) patchContext(ctx context.Context, body interface{}, path ...string) error {
 	marshalJSON, err := jsonMarshal(body)
 	if err != nil {
 		return err
@@ -488,7 +685,7 @@ func (b *BigIP) patch(body interface{}, path ...string) error {
 		ContentType: "application/json",
 	}
 
-	_, callErr := b.APICall(req)
+	_, callErr := b.APICallContext(ctx, req)
 	return callErr
 }
 
@@ -509,80 +706,6 @@ func (b *BigIP) fastPatch(body interface{}, path ...string) ([]byte, error) {
 	return resp, callErr
 }
 
-// Upload a file read from a Reader
-func (b *BigIP) Upload(r io.Reader, size int64, path ...string) (*Upload, error) {
-	options := &APIRequest{
-		Method:      "post",
-		URL:         b.iControlPath(path),
-		ContentType: "application/octet-stream",
-	}
-	var format string
-	if strings.Contains(options.URL, "mgmt/") {
-		format = "%s/%s"
-	} else {
-		format = "%s/mgmt/%s"
-	}
-	urlString := fmt.Sprintf(format, b.Host, options.URL)
-	chunkSize := 512 * 1024
-	var start, end int64
-	for {
-		// Read next chunk
-		chunk := make([]byte, chunkSize)
-		n, err := r.Read(chunk)
-		if err != nil {
-			return nil, err
-		}
-		end = start + int64(n)
-		// Resize buffer size to number of bytes read
-		if n < chunkSize {
-			chunk = chunk[:n]
-		}
-		body := bytes.NewReader(chunk)
-		req, err := http.NewRequest(strings.ToUpper(options.Method), urlString, body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create HTTP request: %w", err)
-		}
-		if b.Token != "" {
-			req.Header.Set("X-F5-Auth-Token", b.Token)
-		} else {
-			req.SetBasicAuth(b.User, b.Password)
-		}
-		req.Header.Add("Content-Type", options.ContentType)
-		req.Header.Add("Content-Range", fmt.Sprintf("%d-%d/%d", start, end-1, size))
-		b.Transport.Proxy = func(reqNew *http.Request) (*url.URL, error) {
-			return http.ProxyFromEnvironment(reqNew)
-		}
-		client := &http.Client{
-			Transport: b.Transport,
-			Timeout:   b.ConfigOptions.APICallTimeout,
-		}
-		// Try to upload chunk
-		res, err := client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		data, _ := io.ReadAll(res.Body)
-		if res.StatusCode >= 400 {
-			if res.Header.Get("Content-Type") == "application/json" {
-				return nil, b.checkError(data)
-			}
-
-			return nil, fmt.Errorf("HTTP %d :: %s", res.StatusCode, string(data[:]))
-		}
-		defer res.Body.Close()
-		var upload Upload
-		err = json.Unmarshal(data, &upload)
-		if err != nil {
-			return nil, err
-		}
-		start = end
-		if start >= size {
-			// Final chunk was uploaded
-			return &upload, err
-		}
-	}
-}
-
 func (b *BigIP) getSetting(path ...string) (error, []byte) {
 	req := &APIRequest{
 		Method:      "get",
@@ -613,21 +736,22 @@ func (b *BigIP) getSetting(path ...string) (error, []byte) {
 
 // Get a urlString and populate an entity. If the entity does not exist (404) then the
 // passed entity will be untouched and false will be returned as the second parameter.
-// You can use this to distinguish between a missing entity or an actual error.
+// The error is an *APIError in that case; use IsNotFound to distinguish between a
+// missing entity or an actual error.
 func (b *BigIP) getForEntity(e interface{}, path ...string) (error, bool) {
+	return b.getForEntityContext(b.context(), e, path...)
+}
+
+// getForEntityContext is the context-aware variant of getForEntity.
+func (b *BigIP) getForEntityContext(ctx context.Context, e interface{}, path ...string) (error, bool) {
 	req := &APIRequest{
 		Method:      "get",
 		URL:         b.iControlPath(path),
 		ContentType: "application/json",
 	}
 
-	resp, err := b.APICall(req)
+	resp, err := b.APICallContext(ctx, req)
 	if err != nil {
-		var reqError RequestError
-		json.Unmarshal(resp, &reqError)
-		if reqError.Code == 404 {
-			return err, false
-		}
 		return err, false
 	}
 
@@ -648,8 +772,6 @@ func (b *BigIP) getForEntityNew(e interface{}, path ...string) (error, bool) {
 
 	resp, err := b.APICall(req)
 	if err != nil {
-		var reqError RequestError
-		json.Unmarshal(resp, &reqError)
 		return err, false
 	}
 	err = json.Unmarshal(resp, e)
@@ -659,28 +781,6 @@ func (b *BigIP) getForEntityNew(e interface{}, path ...string) (error, bool) {
 	return nil, true
 }
 
-// checkError handles any errors we get from our API requests. It returns either the
-// message of the error, if any, or nil.
-func (b *BigIP) checkError(resp []byte) error {
-	if len(resp) == 0 {
-		return nil
-	}
-
-	var reqError RequestError
-
-	err := json.Unmarshal(resp, &reqError)
-	if err != nil {
-		return errors.New(fmt.Sprintf("%s\n%s", err.Error(), string(resp[:])))
-	}
-
-	err = reqError.Error()
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
 // jsonMarshal specifies an encoder with 'SetEscapeHTML' set to 'false' so that <, >, and & are not escaped. https://golang.org/pkg/encoding/json/#Marshal
 // https://stackoverflow.com/questions/28595664/how-to-stop-json-marshal-from-escaping-and
 func jsonMarshal(t interface{}) ([]byte, error) {
@@ -0,0 +1,1013 @@
+package bigip
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type BigIPTestSuite struct {
+	suite.Suite
+	Client       *BigIP
+	Server       *httptest.Server
+	ResponseFunc func(http.ResponseWriter, *http.Request)
+}
+
+func (s *BigIPTestSuite) SetupSuite() {
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.ResponseFunc != nil {
+			s.ResponseFunc(w, r)
+		}
+	}))
+	config := &Config{
+		Address:           s.Server.URL,
+		CertVerifyDisable: true,
+	}
+
+	s.Client = NewSession(config)
+}
+
+func (s *BigIPTestSuite) TearDownSuite() {
+	s.Server.Close()
+}
+
+func (s *BigIPTestSuite) SetupTest() {
+	s.ResponseFunc = nil
+}
+
+func TestBigIPSuite(t *testing.T) {
+	suite.Run(t, new(BigIPTestSuite))
+}
+
+func (s *BigIPTestSuite) TestAPICallContextStopsRetries() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"code":503,"message":"service unavailable"}`))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := s.Client.APICallContext(ctx, &APIRequest{Method: "get", URL: "net/vlan"})
+
+	assert.True(s.T(), errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+	assert.True(s.T(), time.Since(start) < 5*time.Second)
+}
+
+func (s *BigIPTestSuite) TestWithContext() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items":[]}`))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.Client.WithContext(ctx).Vlans()
+	assert.True(s.T(), errors.Is(err, context.Canceled), "unexpected error: %v", err)
+
+	_, err = s.Client.Vlans()
+	assert.Nil(s.T(), err)
+}
+
+func (s *BigIPTestSuite) TestAPIErrorNotFound() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":404,"message":"01020036:3: The requested Pool (/Common/missing) was not found.","errorStack":["stack-1"]}`))
+	}
+
+	pool, err := s.Client.GetPool("/Common/missing")
+
+	assert.Nil(s.T(), pool)
+	assert.True(s.T(), IsNotFound(err))
+	assert.False(s.T(), IsValidationError(err))
+	assert.Equal(s.T(), "01020036:3: The requested Pool (/Common/missing) was not found.", err.Error())
+	var apiErr *APIError
+	if assert.True(s.T(), errors.As(err, &apiErr)) {
+		assert.Equal(s.T(), http.StatusNotFound, apiErr.StatusCode)
+		assert.Equal(s.T(), "GET", apiErr.Method)
+		assert.Equal(s.T(), s.Server.URL+"/mgmt/tm/ltm/pool/~Common~missing", apiErr.URL)
+		assert.Equal(s.T(), []string{"stack-1"}, apiErr.ErrorStack)
+	}
+}
+
+func (s *BigIPTestSuite) TestAPIErrorPlainBody() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte("object already exists"))
+	}
+
+	err := s.Client.CreatePool("/Common/dup")
+
+	assert.True(s.T(), IsConflict(err))
+	assert.True(s.T(), IsAlreadyExists(err))
+	assert.Equal(s.T(), "HTTP 409 :: object already exists", err.Error())
+}
+
+func (s *BigIPTestSuite) newClient(options *ConfigOptions) *BigIP {
+	return NewSession(&Config{
+		Address:           s.Server.URL,
+		CertVerifyDisable: true,
+		ConfigOptions:     options,
+	})
+}
+
+func (s *BigIPTestSuite) TestRetryPolicyRetriesUnavailable() {
+	var calls int32
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"code":503,"message":"there is an active asynchronous task executing"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}
+	client := s.newClient(&ConfigOptions{
+		APICallTimeout: 5 * time.Second,
+		RetryPolicy:    &ExponentialBackoff{MaxAttempts: 5, BaseDelay: time.Millisecond},
+	})
+
+	err := client.post(map[string]string{"name": "pool"}, uriLtm, uriPool)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int32(3), atomic.LoadInt32(&calls))
+}
+
+func (s *BigIPTestSuite) TestRetryPolicyDoesNotReplayPost() {
+	var calls int32
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	client := s.newClient(&ConfigOptions{
+		APICallTimeout: 5 * time.Second,
+		RetryPolicy:    &ExponentialBackoff{MaxAttempts: 5, BaseDelay: time.Millisecond},
+	})
+
+	err := client.post(map[string]string{"name": "pool"}, uriLtm, uriPool)
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), int32(1), atomic.LoadInt32(&calls))
+
+	_, err = client.Pools()
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), int32(6), atomic.LoadInt32(&calls))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	policy := &ExponentialBackoff{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+	unavailable := &APIError{StatusCode: http.StatusServiceUnavailable}
+
+	delay, retry := policy.ShouldRetry(&RetryAttempt{Attempt: 1, Method: "POST", Err: unavailable})
+	assert.True(t, retry)
+	assert.True(t, delay >= 500*time.Millisecond && delay <= time.Second, "delay %s", delay)
+
+	delay, retry = policy.ShouldRetry(&RetryAttempt{Attempt: 2, Method: "GET", Err: unavailable, RetryAfter: 7 * time.Second})
+	assert.True(t, retry)
+	assert.Equal(t, 7*time.Second, delay)
+
+	_, retry = policy.ShouldRetry(&RetryAttempt{Attempt: 3, Method: "GET", Err: unavailable})
+	assert.False(t, retry)
+
+	_, retry = policy.ShouldRetry(&RetryAttempt{Attempt: 1, Method: "GET", Err: &APIError{StatusCode: http.StatusNotFound}})
+	assert.False(t, retry)
+
+	_, retry = policy.ShouldRetry(&RetryAttempt{Attempt: 1, Method: "POST", Err: errors.New("connection reset")})
+	assert.False(t, retry)
+
+	_, retry = policy.ShouldRetry(&RetryAttempt{Attempt: 1, Method: "POST", Idempotent: true, Err: errors.New("connection reset")})
+	assert.True(t, retry)
+}
+
+func (s *BigIPTestSuite) TestTokenSessionRelogin() {
+	var logins int32
+	var deleted string
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/mgmt/shared/authn/login":
+			n := atomic.AddInt32(&logins, 1)
+			w.Write([]byte(fmt.Sprintf(`{"token":{"token":"token-%d"},"timeout":{"timeout":1200}}`, n)))
+		case strings.HasPrefix(r.URL.Path, "/mgmt/shared/authz/tokens/") && r.Method == "DELETE":
+			deleted = strings.TrimPrefix(r.URL.Path, "/mgmt/shared/authz/tokens/")
+			w.Write([]byte(`{}`))
+		case r.Header.Get("X-F5-Auth-Token") != "token-2":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":401,"message":"X-F5-Auth-Token does not exist."}`))
+		default:
+			w.Write([]byte(`{"items":[]}`))
+		}
+	}
+
+	client, err := NewTokenSession(&Config{
+		Address:           s.Server.URL,
+		Username:          "admin",
+		Password:          "secret",
+		CertVerifyDisable: true,
+	})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "token-1", client.Token)
+
+	_, err = client.Pools()
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int32(2), atomic.LoadInt32(&logins))
+	assert.Equal(s.T(), "token-2", client.CurrentToken())
+	assert.Equal(s.T(), "token-1", client.Token)
+
+	assert.Nil(s.T(), client.Close())
+	assert.Equal(s.T(), "token-2", deleted)
+	assert.Equal(s.T(), "", client.CurrentToken())
+}
+
+func (s *BigIPTestSuite) TestTokenSessionConcurrentRefresh() {
+	var logins int32
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/mgmt/shared/authn/login" {
+			n := atomic.AddInt32(&logins, 1)
+			w.Write([]byte(fmt.Sprintf(`{"token":{"token":"token-%d"},"timeout":{"timeout":1200}}`, n)))
+			return
+		}
+		w.Write([]byte(`{"items":[]}`))
+	}
+	client, err := NewTokenSession(&Config{
+		Address:           s.Server.URL,
+		Username:          "admin",
+		Password:          "secret",
+		CertVerifyDisable: true,
+	})
+	assert.Nil(s.T(), err)
+
+	// Copies of the client are made and used while the token is replaced
+	// underneath them.
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				c := client.WithContext(context.Background())
+				for j := 0; j < 100; j++ {
+					c = client.WithContext(context.Background())
+				}
+				if _, err := c.Pools(); err != nil && errs[i] == nil {
+					errs[i] = err
+				}
+			}
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		assert.Nil(s.T(), client.relogin(context.Background(), client.CurrentToken()))
+	}
+	close(done)
+	wg.Wait()
+	for _, err := range errs {
+		assert.Nil(s.T(), err)
+	}
+	assert.Equal(s.T(), "token-11", client.CurrentToken())
+	assert.Equal(s.T(), "token-1", client.Token)
+}
+
+func (s *BigIPTestSuite) TestTokenSessionTransfers() {
+	var logins int32
+	valid := "token-2"
+	content := []byte("0123456789")
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/mgmt/shared/authn/login":
+			n := atomic.AddInt32(&logins, 1)
+			w.Write([]byte(fmt.Sprintf(`{"token":{"token":"token-%d"},"timeout":{"timeout":1200}}`, n)))
+		case r.Header.Get("X-F5-Auth-Token") != valid:
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":401,"message":"X-F5-Auth-Token does not exist."}`))
+		case strings.HasPrefix(r.URL.Path, "/mgmt/shared/file-transfer/uploads/"):
+			w.Write([]byte(`{"remainingByteCount":0,"totalByteCount":10,"localFilePath":"/var/config/rest/downloads/app.tar.gz"}`))
+		default:
+			serveRanges(content)(w, r)
+		}
+	}
+	client, err := NewTokenSession(&Config{
+		Address:           s.Server.URL,
+		Username:          "admin",
+		Password:          "secret",
+		CertVerifyDisable: true,
+	})
+	assert.Nil(s.T(), err)
+
+	_, err = client.UploadBytes(content, "app.tar.gz")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "token-2", client.CurrentToken())
+
+	valid = "token-3"
+	var buf bytes.Buffer
+	n, err := client.Download(context.Background(), "mgmt/shared/file-transfer/ucs-downloads/backup.ucs", &buf)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int64(len(content)), n)
+	assert.Equal(s.T(), "token-3", client.CurrentToken())
+	assert.Equal(s.T(), int32(3), atomic.LoadInt32(&logins))
+}
+
+func (s *BigIPTestSuite) TestInterceptors() {
+	var seen []string
+	var gotHeader string
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Request-Id")
+		w.Write([]byte(`{"items":[]}`))
+	}
+	tag := func(name string) Interceptor {
+		return func(next CallHandler) CallHandler {
+			return func(call *Call) (*CallResponse, error) {
+				seen = append(seen, name+">")
+				call.Request.Header.Set("X-Request-Id", name)
+				res, err := next(call)
+				if err == nil {
+					seen = append(seen, fmt.Sprintf("<%s %s %s %d", name, call.APIRequest.Method, call.APIRequest.URL, res.StatusCode))
+					assert.True(s.T(), res.Duration > 0)
+				}
+				return res, err
+			}
+		}
+	}
+	client := NewSession(&Config{
+		Address:           s.Server.URL,
+		CertVerifyDisable: true,
+		Interceptors:      []Interceptor{tag("outer"), tag("inner")},
+	})
+
+	_, err := client.Pools()
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "inner", gotHeader)
+	assert.Equal(s.T(), []string{"outer>", "inner>", "<inner get ltm/pool 200", "<outer get ltm/pool 200"}, seen)
+}
+
+func TestRedact(t *testing.T) {
+	body := RedactBody([]byte(`{"username":"admin","password":"s3cr\"et","token":{"token":"abc"}}`))
+	assert.Equal(t, `{"username":"admin","password":"REDACTED","token":{"token":"REDACTED"}}`, string(body))
+
+	h := http.Header{}
+	h.Set("X-F5-Auth-Token", "abc")
+	h.Set("Content-Type", "application/json")
+	redacted := RedactHeader(h)
+	assert.Equal(t, "REDACTED", redacted.Get("X-F5-Auth-Token"))
+	assert.Equal(t, "application/json", redacted.Get("Content-Type"))
+	assert.Equal(t, "abc", h.Get("X-F5-Auth-Token"))
+}
+
+func (s *BigIPTestSuite) TestLogger() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items":[]}`))
+	}
+	var buf bytes.Buffer
+	client := NewSession(&Config{
+		Address:           s.Server.URL,
+		CertVerifyDisable: true,
+		Logger:            slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+
+	_, err := client.Pools()
+
+	assert.Nil(s.T(), err)
+	assert.Contains(s.T(), buf.String(), `msg="API call" method=GET path=ltm/pool status=200 attempt=1`)
+}
+
+func TestTLSConfig(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	clientCertPEM, clientKeyPEM := newTestCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(clientCertPEM)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	sum := sha256.Sum256(server.Certificate().Raw)
+	pin := hex.EncodeToString(sum[:])
+
+	for _, tc := range []struct {
+		name   string
+		config Config
+		ok     bool
+	}{
+		{"ca bundle", Config{TrustedCertificatePEM: serverCA}, true},
+		{"no client certificate", Config{TrustedCertificatePEM: serverCA, ClientCertificatePEM: nil}, false},
+		{"pin", Config{CertificateFingerprint: pin}, true},
+		{"pin and ca", Config{CertificateFingerprint: strings.ToUpper(pin), TrustedCertificatePEM: serverCA}, true},
+		{"wrong pin", Config{CertificateFingerprint: strings.Repeat("00", 32)}, false},
+		{"unknown ca", Config{}, false},
+		{"tls version", Config{TrustedCertificatePEM: serverCA, MinTLSVersion: tls.VersionTLS13}, true},
+	} {
+		config := tc.config
+		config.Address = server.URL
+		config.ConfigOptions = &ConfigOptions{APICallTimeout: 5 * time.Second, APICallRetries: 1}
+		if tc.name != "no client certificate" {
+			config.ClientCertificatePEM = append(clientCertPEM, clientKeyPEM...)
+		}
+		_, err := NewSession(&config).Pools()
+		assert.Equal(t, tc.ok, err == nil, "%s: %v", tc.name, err)
+	}
+
+	_, err := NewSession(&Config{Address: server.URL, TrustedCertificate: "/nonexistent.pem"}).Pools()
+	assert.NotNil(t, err)
+	_, err = OpenSession(&Config{Address: server.URL, TrustedCertificate: "/nonexistent.pem"})
+	assert.NotNil(t, err)
+	client, err := OpenSession(&Config{Address: server.URL, TrustedCertificatePEM: serverCA})
+	assert.Nil(t, err)
+	assert.NotNil(t, client)
+	_, err = NewTokenSession(&Config{Address: server.URL, CertificateFingerprint: "abc"})
+	assert.NotNil(t, err)
+}
+
+func newTestCertificate(t *testing.T) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "go-bigip-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func (s *BigIPTestSuite) TestResource() {
+	var requests []string
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, strings.TrimSpace(r.Method+" "+r.URL.Path+" "+string(body)))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/mgmt/tm/ltm/profile/udp":
+			if r.Method == http.MethodGet {
+				w.Write([]byte(`{"items":[{"name":"udp"},{"name":"udp_gtm_dns"}]}`))
+				return
+			}
+		case "/mgmt/tm/ltm/profile/udp/~Common~missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"not found"}`))
+			return
+		}
+		w.Write([]byte(`{"name":"udp","idleTimeout":"60"}`))
+	}
+	udp := NewResource[UdpProfile](s.Client, "ltm", "profile", "udp")
+
+	items, err := udp.List()
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, len(items))
+	assert.Equal(s.T(), "udp_gtm_dns", items[1].Name)
+
+	profile, err := udp.Get("/Common/udp")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "60", profile.IdleTimeout)
+
+	exists, err := udp.Exists("/Common/missing")
+	assert.Nil(s.T(), err)
+	assert.False(s.T(), exists)
+	_, err = udp.Get("/Common/missing")
+	assert.True(s.T(), IsNotFound(err))
+
+	assert.Nil(s.T(), udp.Create(&UdpProfile{Name: "new"}))
+	assert.Nil(s.T(), udp.Update("/Common/new", &UdpProfile{Name: "new"}))
+	assert.Nil(s.T(), udp.Patch("/Common/new", &UdpProfile{IdleTimeout: "10"}))
+	assert.Nil(s.T(), udp.Delete("/Common/new"))
+
+	wrapped, err := s.Client.UDPProfiles()
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), items, wrapped.UdpProfiles)
+
+	assert.Equal(s.T(), []string{
+		"GET /mgmt/tm/ltm/profile/udp",
+		"GET /mgmt/tm/ltm/profile/udp/~Common~udp",
+		"GET /mgmt/tm/ltm/profile/udp/~Common~missing",
+		"GET /mgmt/tm/ltm/profile/udp/~Common~missing",
+		`POST /mgmt/tm/ltm/profile/udp {"name":"new"}`,
+		`PUT /mgmt/tm/ltm/profile/udp/~Common~new {"name":"new"}`,
+		`PATCH /mgmt/tm/ltm/profile/udp/~Common~new {"idleTimeout":"10"}`,
+		"DELETE /mgmt/tm/ltm/profile/udp/~Common~new",
+		"GET /mgmt/tm/ltm/profile/udp",
+	}, requests)
+}
+
+func (s *BigIPTestSuite) TestListOptions() {
+	var queries []string
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("$skip") {
+		case "":
+			w.Write([]byte(`{"items":[{"name":"a"},{"name":"b"}],"nextLink":"https://localhost/mgmt/tm/ltm/pool?$filter=partition%20eq%20Prod&$skip=2&$top=2","totalItems":3}`))
+		default:
+			w.Write([]byte(`{"items":[{"name":"c"}],"totalItems":3}`))
+		}
+	}
+	opts := &ListOptions{Top: 2, Filter: "partition eq Prod", Select: []string{"name", "fullPath"}, ExpandSubcollections: true}
+
+	page, err := NewResource[Pool](s.Client, uriLtm, uriPool).ListPage(opts)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, len(page.Items))
+	assert.Equal(s.T(), 3, page.TotalItems)
+	assert.Equal(s.T(), "$top=2&$filter=partition%20eq%20Prod&$select=name%2CfullPath&expandSubcollections=true", queries[0])
+
+	pools, err := s.Client.PoolsWithOptions(opts)
+	assert.Nil(s.T(), err)
+	var names []string
+	for _, p := range pools.Pools {
+		names = append(names, p.Name)
+	}
+	assert.Equal(s.T(), []string{"a", "b", "c"}, names)
+	assert.Equal(s.T(), "$filter=partition%20eq%20Prod&$skip=2&$top=2", queries[2])
+}
+
+func (s *BigIPTestSuite) TestIteratorError() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("$skip") == "" {
+			w.Write([]byte(`{"items":[{"name":"a"}],"nextLink":"https://localhost/mgmt/tm/ltm/node?$skip=1&$top=1"}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":400,"message":"bad page"}`))
+	}
+
+	it := NewResource[Node](s.Client, uriLtm, uriNode).Iterate(&ListOptions{Top: 1})
+	assert.True(s.T(), it.Next())
+	assert.Equal(s.T(), "a", it.Item().Name)
+	assert.False(s.T(), it.Next())
+	assert.True(s.T(), IsValidationError(it.Err()))
+}
+
+// shortenPollInterval makes the waits polling with interval fast for the
+// rest of the test.
+func shortenPollInterval(t *testing.T, interval *time.Duration) {
+	saved := *interval
+	*interval = time.Millisecond
+	t.Cleanup(func() { *interval = saved })
+}
+
+func (s *BigIPTestSuite) TestWithTransaction() {
+	shortenPollInterval(s.T(), &transactionPollInterval)
+	var mu sync.Mutex
+	var requests []string
+	polls := 0
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+r.Header.Get("X-F5-REST-Coordination-Id"))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/mgmt/tm/transaction":
+			w.Write([]byte(`{"transId":42,"state":"STARTED"}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/mgmt/tm/transaction/42":
+			w.Write([]byte(`{"transId":42,"state":"VALIDATING"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/mgmt/tm/transaction/42":
+			if polls++; polls < 2 {
+				w.Write([]byte(`{"transId":42,"state":"EXECUTING"}`))
+				return
+			}
+			w.Write([]byte(`{"transId":42,"state":"FAILED","failureReason":"01020066:3: The requested Pool (/Common/web) already exists in partition Common."}`))
+		case r.URL.Path == "/mgmt/tm/transaction/42/commands":
+			w.Write([]byte(`{"items":[{"commandId":1,"method":"POST","uri":"https://localhost/mgmt/tm/ltm/node","body":{"name":"we"}},{"commandId":2,"method":"POST","uri":"https://localhost/mgmt/tm/ltm/pool","body":{"name":"web","partition":"Common","fullPath":"/Common/web"}}]}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}
+
+	err := s.Client.WithTransaction(func(tx *Tx) error {
+		if err := tx.Client().CreatePool("/Common/web"); err != nil {
+			return err
+		}
+		_, err := s.Client.Vlans()
+		return err
+	})
+
+	var txErr *TransactionError
+	if assert.True(s.T(), errors.As(err, &txErr), "unexpected error: %v", err) {
+		assert.Equal(s.T(), int64(42), txErr.ID)
+		assert.Equal(s.T(), int64(2), txErr.Command.CommandID)
+	}
+	assert.Equal(s.T(), []string{
+		"POST /mgmt/tm/transaction ",
+		"POST /mgmt/tm/ltm/pool 42",
+		"GET /mgmt/tm/net/vlan ",
+		"PATCH /mgmt/tm/transaction/42 ",
+		"GET /mgmt/tm/transaction/42 ",
+		"GET /mgmt/tm/transaction/42 ",
+		"GET /mgmt/tm/transaction/42/commands ",
+		"DELETE /mgmt/tm/transaction/42 ",
+	}, requests)
+
+	requests = nil
+	err = s.Client.WithTransaction(func(tx *Tx) error {
+		return errors.New("abort")
+	})
+	assert.Equal(s.T(), "abort", err.Error())
+	assert.Equal(s.T(), []string{"POST /mgmt/tm/transaction ", "DELETE /mgmt/tm/transaction/42 "}, requests)
+
+	requests = nil
+	assert.Panics(s.T(), func() {
+		s.Client.WithTransaction(func(tx *Tx) error {
+			panic("abort")
+		})
+	})
+	assert.Equal(s.T(), []string{"POST /mgmt/tm/transaction ", "DELETE /mgmt/tm/transaction/42 "}, requests)
+}
+
+func TestFailedCommand(t *testing.T) {
+	commands := []TransactionCommand{
+		{EvalOrder: 1, Method: "POST", URI: "https://localhost/mgmt/tm/ltm/node", Body: json.RawMessage(`{"name":"n1"}`)},
+		{EvalOrder: 2, Method: "PATCH", URI: "https://localhost/mgmt/tm/ltm/node/~Common~n10", Body: json.RawMessage(`{"description":"x"}`)},
+		{EvalOrder: 3, Method: "DELETE", URI: "https://localhost/mgmt/tm/ltm/pool/web"},
+	}
+	for reason, order := range map[string]int64{
+		"01020036:3: The requested Node (/Common/n10) was not found.": 2,
+		"Node /Common/n1 already exists.":                             1,
+		"01070734:3: Configuration error: command 3 failed":           3,
+		"The requested Pool (/Common/web) was not found.":             0,
+		"unknown error": 0,
+	} {
+		command := failedCommand(reason, commands)
+		if order == 0 {
+			assert.Nil(t, command, reason)
+		} else if assert.NotNil(t, command, reason) {
+			assert.Equal(t, order, command.EvalOrder, reason)
+		}
+	}
+}
+
+func (s *BigIPTestSuite) TestStartTransaction() {
+	var mu sync.Mutex
+	var requests []string
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+r.Header.Get("X-F5-REST-Coordination-Id"))
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost && r.URL.Path == "/mgmt/tm/transaction" {
+			w.Write([]byte(`{"transId":7}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}
+	client := s.newClient(nil)
+	derived := client.WithContext(context.Background())
+
+	t, err := client.StartTransaction()
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "7", client.Transaction)
+	stale := client.WithContext(context.Background())
+	assert.Nil(s.T(), derived.CreatePool("web"))
+	assert.Nil(s.T(), client.CommitTransaction(t.TransID))
+	assert.Equal(s.T(), "", client.Transaction)
+	assert.Nil(s.T(), stale.CreatePool("web"))
+
+	assert.Equal(s.T(), []string{
+		"POST /mgmt/tm/transaction ",
+		"POST /mgmt/tm/ltm/pool 7",
+		"PATCH /mgmt/tm/transaction/7 ",
+		"POST /mgmt/tm/ltm/pool ",
+	}, requests)
+}
+
+func (s *BigIPTestSuite) TestHTTPClientFollowsSettings() {
+	client := s.newClient(nil)
+	assert.True(s.T(), client.httpClient() == client.client)
+
+	client.ConfigOptions.APICallTimeout = time.Second
+	assert.Equal(s.T(), time.Second, client.httpClient().Timeout)
+
+	transport := &http.Transport{}
+	client.Transport = transport
+	assert.True(s.T(), client.httpClient().Transport == transport)
+}
+
+func (s *BigIPTestSuite) TestStats() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mgmt/tm/ltm/virtual/~Common~vs/stats":
+			w.Write([]byte(`{"kind":"tm:ltm:virtual:virtualstats","entries":{"https://localhost/mgmt/tm/ltm/virtual/~Common~vs/stats":{"nestedStats":{"entries":{
+				"clientside.bitsIn":{"value":18446744073709551615},
+				"clientside.curConns":{"value":12},
+				"status.availabilityState":{"description":"available"},
+				"tmName":{"description":"/Common/vs"}}}}}}`))
+		case "/mgmt/tm/ltm/pool/~Common~web/members/stats":
+			w.Write([]byte(`{"entries":{
+				"https://localhost/mgmt/tm/ltm/pool/~Common~web/members/~Common~10.0.0.1:80/stats?ver=17.1.0":{"nestedStats":{"entries":{"serverside.curConns":{"value":3}}}},
+				"https://localhost/mgmt/tm/ltm/pool/~Common~web/members/~Common~10.0.0.2:80/stats?ver=17.1.0":{"nestedStats":{"entries":{"serverside.curConns":{"value":4}}}}}}`))
+		case "/mgmt/tm/sys/host-info":
+			w.Write([]byte(`{"entries":{"https://localhost/mgmt/tm/sys/host-info/0":{"nestedStats":{"entries":{
+				"memoryTotal":{"value":8388608},
+				"https://localhost/mgmt/tm/sys/hostInfo/0/cpuInfo":{"nestedStats":{"entries":{
+					"https://localhost/mgmt/tm/sys/hostInfo/0/cpuInfo/0":{"nestedStats":{"entries":{"cpuId":{"value":0},"oneMinAvgIdle":{"value":97}}}}}}}}}}}}`))
+		case "/mgmt/tm/ltm/node/~Common~n1/stats":
+			// Versions before 12.0 report the stats of an object directly.
+			w.Write([]byte(`{"selfLink":"https://localhost/mgmt/tm/ltm/node/~Common~n1/stats?ver=11.6.0","entries":{"curSessions":{"value":2}}}`))
+		}
+	}
+
+	vs, err := s.Client.VirtualServerStats("/Common/vs")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "/Common/vs", vs.Name)
+	assert.Equal(s.T(), int64(12), vs.Values["clientside.curConns"])
+	assert.Equal(s.T(), int64(math.MaxInt64), vs.Values["clientside.bitsIn"])
+	assert.Equal(s.T(), "available", vs.Descriptions["status.availabilityState"])
+
+	members, err := s.Client.PoolMemberStats("/Common/web")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, len(members))
+	assert.Equal(s.T(), int64(4), members["/Common/10.0.0.2:80"].Values["serverside.curConns"])
+
+	hosts, err := s.Client.HostInfoStats()
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int64(8388608), hosts["0"].Values["memoryTotal"])
+	assert.Equal(s.T(), int64(97), hosts["0"].Nested["cpuInfo"]["0"].Values["oneMinAvgIdle"])
+
+	node, err := s.Client.getObjectStats(uriLtm, uriNode, "/Common/n1", uriStats)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "/Common/n1", node.Name)
+	assert.Equal(s.T(), int64(2), node.Values["curSessions"])
+}
+
+func memberStats(name, availability, enabled, reason string) string {
+	return fmt.Sprintf(`"https://localhost/mgmt/tm/ltm/pool/~Common~web/members/%s/stats":{"nestedStats":{"entries":{"status.availabilityState":{"description":"%s"},"status.enabledState":{"description":"%s"},"status.statusReason":{"description":"%s"}}}}`,
+		strings.ReplaceAll(name, "/", "~"), availability, enabled, reason)
+}
+
+func (s *BigIPTestSuite) TestPoolHealth() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mgmt/tm/ltm/pool/~Common~web/stats":
+			w.Write([]byte(`{"entries":{"https://localhost/mgmt/tm/ltm/pool/~Common~web/stats":{"nestedStats":{"entries":{"status.availabilityState":{"description":"available"},"status.enabledState":{"description":"enabled"},"status.statusReason":{"description":"The pool is available"}}}}}}`))
+		case "/mgmt/tm/ltm/pool/~Common~web/members/stats":
+			w.Write([]byte(`{"entries":{` + strings.Join([]string{
+				memberStats("/Common/a:80", "available", "enabled", "Pool member is available"),
+				memberStats("/Common/b:80", "offline", "enabled", "Pool member has been marked down by a monitor"),
+				memberStats("/Common/c:80", "available", "disabled", "Pool member is available, user disabled"),
+				memberStats("/Common/d:80", "unknown", "enabled", "Pool member does not have service checking enabled"),
+			}, ",") + `}}`))
+		}
+	}
+
+	health, err := s.Client.PoolHealth("/Common/web")
+	assert.Nil(s.T(), err)
+	assert.True(s.T(), health.Pool.Available())
+	assert.Equal(s.T(), []int{1, 1, 1, 1}, []int{health.Available, health.Offline, health.Disabled, health.Unknown})
+	assert.Equal(s.T(), Availability{
+		Name:              "/Common/b:80",
+		AvailabilityState: "offline",
+		EnabledState:      "enabled",
+		StatusReason:      "Pool member has been marked down by a monitor",
+	}, health.Members[1])
+}
+
+func (s *BigIPTestSuite) TestWaitForPoolMemberState() {
+	shortenPollInterval(s.T(), &statusPollInterval)
+	var polls int32
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		enabled := "enabled"
+		if atomic.AddInt32(&polls, 1) > 2 {
+			enabled = "disabled"
+		}
+		w.Write([]byte(`{"entries":{` + memberStats("/Common/a:80", "available", enabled, "") + `}}`))
+	}
+
+	a, err := s.Client.WaitForPoolMemberState(context.Background(), "/Common/web", "/Common/a:80", "disabled")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "disabled", a.EnabledState)
+	assert.Equal(s.T(), int32(3), atomic.LoadInt32(&polls))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = s.Client.WaitForPoolMemberState(ctx, "/Common/web", "/Common/a:80", "offline")
+	assert.True(s.T(), errors.Is(err, context.Canceled), "unexpected error: %v", err)
+}
+
+func (s *BigIPTestSuite) TestDrainPoolMember() {
+	shortenPollInterval(s.T(), &statusPollInterval)
+	var mu sync.Mutex
+	var states []string
+	conns := []int{5, 2, 0}
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == "PUT" {
+			var node Node
+			json.NewDecoder(r.Body).Decode(&node)
+			states = append(states, node.Session+"/"+node.State)
+			w.Write([]byte(`{}`))
+			return
+		}
+		n := conns[0]
+		if len(conns) > 1 {
+			conns = conns[1:]
+		}
+		fmt.Fprintf(w, `{"entries":{"https://localhost/mgmt/tm/ltm/pool/~Common~web/members/~Common~a:80/stats":{"nestedStats":{"entries":{"serverside.curConns":{"value":%d},"status.availabilityState":{"description":"available"},"status.enabledState":{"description":"enabled"}}}}}}`, n)
+	}
+
+	var progress []int64
+	left, err := s.Client.DrainPoolMember(context.Background(), "/Common/web", "/Common/a:80", &DrainOptions{
+		Progress: func(c int64) { progress = append(progress, c) },
+	})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int64(0), left)
+	assert.Equal(s.T(), []int64{5, 2, 0}, progress)
+	assert.Equal(s.T(), []string{"user-disabled/", "user-disabled/user-down"}, states)
+
+	states, conns = nil, []int{3}
+	left, err = s.Client.DrainPoolMember(context.Background(), "/Common/web", "/Common/a:80", &DrainOptions{MaxConnections: 1, Timeout: 10 * time.Millisecond})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int64(3), left)
+	assert.Equal(s.T(), []string{"user-disabled/", "user-disabled/user-down"}, states)
+
+	states = nil
+	a, err := s.Client.UndrainPoolMember(context.Background(), "/Common/web", "/Common/a:80")
+	assert.Nil(s.T(), err)
+	assert.True(s.T(), a.Available())
+	assert.Equal(s.T(), []string{"user-enabled/user-up"}, states)
+
+	states = nil
+	assert.Nil(s.T(), s.Client.PoolMemberStatus("/Common/web", "/Common/a:80", "enable"))
+	assert.Equal(s.T(), []string{"user-enabled/"}, states)
+}
+
+// serveRanges answers like the file-transfer endpoints, honouring the
+// Content-Range request header.
+func serveRanges(content []byte) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var start, end, total int64
+		fmt.Sscanf(r.Header.Get("Content-Range"), "%d-%d/%d", &start, &end, &total)
+		if start >= int64(len(content)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		end = min(end, int64(len(content))-1)
+		w.Header().Set("Content-Range", fmt.Sprintf("%d-%d/%d", start, end, len(content)))
+		w.Write(content[start : end+1])
+	}
+}
+
+func (s *BigIPTestSuite) TestUpload() {
+	content := []byte("0123456789abcdefghij")
+	sum := sha256.Sum256(content)
+	var mu sync.Mutex
+	var received []byte
+	var ranges []string
+	var dropped, unavailable bool
+	remoteSum := hex.EncodeToString(sum[:])
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/mgmt/tm/util/bash" {
+			w.Write([]byte(fmt.Sprintf(`{"command":"run","commandResult":"%d\n%s  /var/config/rest/downloads/app.tar.gz\n"}`, len(content), remoteSum)))
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		cr := r.Header.Get("Content-Range")
+		// Fail the second chunk once with a dropped connection and once
+		// with a 503; both must be retried.
+		if strings.HasPrefix(cr, "8-") && !dropped {
+			dropped = true
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		if strings.HasPrefix(cr, "8-") && !unavailable {
+			unavailable = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var start, end, total int64
+		fmt.Sscanf(cr, "%d-%d/%d", &start, &end, &total)
+		body, _ := io.ReadAll(r.Body)
+		received = append(received[:start], body...)
+		ranges = append(ranges, r.URL.Path+" "+cr)
+		w.Write([]byte(fmt.Sprintf(`{"remainingByteCount":%d,"totalByteCount":%d,"localFilePath":"/var/config/rest/downloads/app.tar.gz"}`, total-end-1, total)))
+	}
+	client := s.newClient(&ConfigOptions{
+		APICallTimeout: 5 * time.Second,
+		RetryPolicy:    &ExponentialBackoff{MaxAttempts: 3, BaseDelay: time.Millisecond},
+	})
+
+	var progress []int64
+	upload, err := client.UploadWithOptions(context.Background(), iotest.HalfReader(bytes.NewReader(content)), int64(len(content)), &UploadOptions{
+		ChunkSize: 8,
+		Progress:  func(done, total int64) { progress = append(progress, done, total) },
+		Verify:    true,
+	}, uriShared, uriFileTransfer, uriUploads, "app.tar.gz")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "/var/config/rest/downloads/app.tar.gz", upload.LocalFilePath)
+	assert.Equal(s.T(), content, received)
+	assert.Equal(s.T(), []string{
+		"/mgmt/shared/file-transfer/uploads/app.tar.gz 0-7/20",
+		"/mgmt/shared/file-transfer/uploads/app.tar.gz 8-15/20",
+		"/mgmt/shared/file-transfer/uploads/app.tar.gz 16-19/20",
+	}, ranges)
+	assert.Equal(s.T(), []int64{8, 20, 16, 20, 20, 20}, progress)
+
+	_, err = client.UploadWithOptions(context.Background(), bytes.NewReader(content[:10]), int64(len(content)), nil, uriShared, uriFileTransfer, uriUploads, "app.tar.gz")
+	assert.True(s.T(), errors.Is(err, io.ErrUnexpectedEOF), "unexpected error: %v", err)
+
+	_, err = client.UploadWithOptions(context.Background(), bytes.NewReader(content), int64(len(content)), &UploadOptions{SHA256: strings.Repeat("0", 64)}, uriShared, uriFileTransfer, uriUploads, "app.tar.gz")
+	assert.True(s.T(), errors.Is(err, ErrChecksumMismatch), "unexpected error: %v", err)
+
+	remoteSum = strings.Repeat("0", 64)
+	_, err = client.UploadWithOptions(context.Background(), bytes.NewReader(content), int64(len(content)), &UploadOptions{Verify: true}, uriShared, uriFileTransfer, uriUploads, "app.tar.gz")
+	assert.True(s.T(), errors.Is(err, ErrChecksumMismatch), "unexpected error: %v", err)
+
+	ranges = nil
+	iso, err := os.Create(s.T().TempDir() + "/BIGIP-17.1.0.iso")
+	assert.Nil(s.T(), err)
+	defer iso.Close()
+	iso.Write(content)
+	iso.Seek(0, io.SeekStart)
+	_, err = client.UploadFile(iso)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), []string{"/mgmt/cm/autodeploy/software-image-uploads/BIGIP-17.1.0.iso 0-19/20"}, ranges)
+}
+
+func (s *BigIPTestSuite) TestDownload() {
+	content := []byte("0123456789abcdefghij")
+	sum := sha256.Sum256(content)
+	s.ResponseFunc = serveRanges(content)
+
+	var buf bytes.Buffer
+	var progress []int64
+	n, err := s.Client.DownloadWithOptions(context.Background(), "mgmt/shared/file-transfer/ucs-downloads/backup.ucs", &buf, &DownloadOptions{
+		ChunkSize: 8,
+		Progress:  func(done, total int64) { progress = append(progress, done, total) },
+		SHA256:    hex.EncodeToString(sum[:]),
+	})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int64(20), n)
+	assert.Equal(s.T(), content, buf.Bytes())
+	assert.Equal(s.T(), []int64{8, 20, 16, 20, 20, 20}, progress)
+
+	buf.Reset()
+	_, err = s.Client.DownloadWithOptions(context.Background(), "mgmt/shared/file-transfer/ucs-downloads/backup.ucs", &buf, &DownloadOptions{SHA256: strings.Repeat("0", 64)})
+	assert.True(s.T(), errors.Is(err, ErrChecksumMismatch), "unexpected error: %v", err)
+
+	local := s.T().TempDir() + "/backup.ucs"
+	for _, partial := range []int{7, 20} {
+		assert.Nil(s.T(), os.WriteFile(local, content[:partial], 0600))
+		n, err = s.Client.DownloadFile(context.Background(), "mgmt/shared/file-transfer/ucs-downloads/backup.ucs", local, &DownloadOptions{
+			ChunkSize: 8,
+			SHA256:    hex.EncodeToString(sum[:]),
+		})
+		assert.Nil(s.T(), err)
+		assert.Equal(s.T(), int64(20-partial), n)
+		got, _ := os.ReadFile(local)
+		assert.Equal(s.T(), content, got)
+	}
+}
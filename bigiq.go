@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"reflect"
 	"strings"
 	"time"
@@ -138,7 +137,7 @@ type BigiqResults struct {
 }
 
 func (b *BigIP) PostLicense(config *LicenseParam) (string, error) {
-	log.Printf("[INFO] %v license to BIGIP device:%v from BIGIQ", config.Command, config.Address)
+	b.log().Info("sending BIG-IQ license task", "command", config.Command, "device", config.Address)
 	resp, err := b.postReq(config, uriMgmt, uriCm, uriDevice, uriTasks, uriLicensing, uriPool, uriManagement)
 	if err != nil {
 		return "", err
@@ -146,7 +145,9 @@ func (b *BigIP) PostLicense(config *LicenseParam) (string, error) {
 	respRef := make(map[string]interface{})
 	json.Unmarshal(resp, &respRef)
 	respID := respRef["id"].(string)
-	time.Sleep(5 * time.Second)
+	if err := sleepContext(b.context(), 5*time.Second); err != nil {
+		return respID, err
+	}
 	return respID, nil
 }
 func (b *BigIP) GetLicenseStatus(id string) (map[string]interface{}, error) {
@@ -164,12 +165,12 @@ func (b *BigIP) GetLicenseStatus(id string) (map[string]interface{}, error) {
 	for licStatus != "FINISHED" {
 		//log.Printf(" status response is :%s", licStatus)
 		if licStatus == "FAILED" {
-			log.Println("[ERROR]License assign/revoke status failed")
+			b.log().Error("BIG-IQ license task failed", "task_id", id)
 			return licRes, nil
 		}
 		return b.GetLicenseStatus(id)
 	}
-	log.Printf("License Assignment is :%s", licStatus)
+	b.log().Debug("BIG-IQ license task finished", "task_id", id, "status", licStatus)
 	return licRes, nil
 }
 
@@ -239,9 +240,8 @@ func (b *BigIP) GetDeviceId(deviceName string) (string, error) {
 		return "", err
 	}
 	for _, d := range self.DevicesInfo {
-		log.Printf("Address=%v,Hostname=%v,UUID=%v", d.Address, d.Hostname, d.UUID)
 		if d.Address == deviceName || d.Hostname == deviceName || d.UUID == deviceName {
-			log.Printf("SelfLink Type=%T,SelfLink=%v", d.SelfLink, d.SelfLink)
+			b.log().Debug("found BIG-IQ managed device", "address", d.Address, "hostname", d.Hostname, "uuid", d.UUID, "self_link", d.SelfLink)
 			return d.SelfLink, nil
 		}
 	}
@@ -282,7 +282,7 @@ func (b *BigIP) GetMemberStatus(poolId, regKey, memId string) (*memberDetail, er
 		return nil, err
 	}
 	for self.Status != "LICENSED" {
-		log.Printf("Member status:%+v", self.Status)
+		b.log().Debug("regkey pool member status", "member_id", memId, "status", self.Status)
 		if self.Status == "INSTALLATION_FAILED" {
 			return &self, fmt.Errorf("INSTALLATION_FAILED with %s", self.Message)
 		}
@@ -291,7 +291,7 @@ func (b *BigIP) GetMemberStatus(poolId, regKey, memId string) (*memberDetail, er
 	return &self, nil
 }
 func (b *BigIP) RegkeylicenseRevoke(poolId, regKey, memId string) error {
-	log.Printf("Deleting License for Member:%+v", memId)
+	b.log().Debug("revoking regkey license", "member_id", memId)
 	_, err := b.deleteReq(uriMgmt, uriCm, uriDevice, uriLicensing, uriPool, uriRegkey, uriLicenses, poolId, uriOfferings, regKey, uriMembers, memId)
 	if err != nil {
 		return err
@@ -301,11 +301,11 @@ func (b *BigIP) RegkeylicenseRevoke(poolId, regKey, memId string) error {
 	if err != nil {
 		return err
 	}
-	log.Printf("Response after delete:%+v", r1)
+	b.log().Debug("regkey license revoked", "member_id", memId, "response", r1)
 	return nil
 }
 func (b *BigIP) LicenseRevoke(config interface{}, poolId, regKey, memId string) error {
-	log.Printf("Deleting License for Member:%+v from LicenseRevoke", memId)
+	b.log().Debug("revoking regkey license", "member_id", memId)
 	_, err := b.deleteReqBody(config, uriMgmt, uriCm, uriDevice, uriLicensing, uriPool, uriRegkey, uriLicenses, poolId, uriOfferings, regKey, uriMembers, memId)
 	if err != nil {
 		return err
@@ -315,7 +315,7 @@ func (b *BigIP) LicenseRevoke(config interface{}, poolId, regKey, memId string)
 	if err != nil {
 		return err
 	}
-	log.Printf("Response after delete:%+v", r1)
+	b.log().Debug("regkey license revoked", "member_id", memId, "response", r1)
 	return nil
 }
 func (b *BigIP) PostAs3Bigiq(as3NewJson string) (error, string) {
@@ -336,12 +336,12 @@ func (b *BigIP) PostAs3Bigiq(as3NewJson string) (error, string) {
 				success_count++
 			}
 			if taskList.Results[i].Code >= 400 {
-				log.Printf("[ERROR] : HTTP %d :: %s for tenant %v", taskList.Results[i].Code, taskList.Results[i].Message, taskList.Results[i].Tenant)
+				b.log().Error("AS3 tenant failed", "status", taskList.Results[i].Code, "message", taskList.Results[i].Message, "tenant", taskList.Results[i].Tenant)
 			}
 			i = i - 1
 		}
 		if success_count == tenant_count {
-			log.Printf("[DEBUG]Sucessfully Created tenants  = %v", tenant_list)
+			b.log().Debug("AS3 tenants created", "tenant", tenant_list)
 		} else if success_count == 0 {
 			return errors.New(fmt.Sprintf("Tenant Creation failed")), ""
 		} else {
@@ -453,7 +453,7 @@ func (b *BigIP) GetAs3Bigiq(targetRef, tenantRef string) (string, error) {
 func (b *BigIP) DeleteAs3Bigiq(as3NewJson string, tenantName string) (error, string) {
 	as3Json, err := tenantTrimToDelete(as3NewJson)
 	if err != nil {
-		log.Println("[ERROR] Error in trimming the as3 json")
+		b.log().Error("failed to trim AS3 declaration", "tenant", tenantName, "error", err)
 		return err, ""
 	}
 	return b.post(as3Json, uriMgmt, uriShared, uriAppsvcs, uriDeclare), ""
@@ -0,0 +1,303 @@
+package bigip
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	uriConfig = "config"
+	uriScript = "script"
+	uriUnixLs = "unix-ls"
+	uriDig    = "dig"
+	uriPing   = "ping"
+)
+
+// runUtil runs the utility at mgmt/tm/util/<util> with args and returns its
+// output. The utilities answer with the output of the command whether it
+// succeeded or not, so callers check the output for errors.
+func (b *BigIP) runUtil(ctx context.Context, util, args string) (string, error) {
+	body, err := jsonMarshal(&BigipCommand{Command: "run", UtilCmdArgs: args})
+	if err != nil {
+		return "", err
+	}
+	resp, err := b.APICallContext(ctx, &APIRequest{
+		Method:      "post",
+		URL:         b.iControlPath([]string{uriMgmt, uriTm, uriUtil, util}),
+		Body:        string(body),
+		ContentType: "application/json",
+	})
+	if err != nil {
+		return "", err
+	}
+	var result BigipCommand
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return "", err
+	}
+	return result.CommandResult, nil
+}
+
+// SysConfigOptions selects what SaveSysConfig, LoadSysConfig and
+// MergeSysConfig act on.
+type SysConfigOptions struct {
+	// File is the single configuration file to save to or load from, such
+	// as "/var/config/rest/downloads/app.scf", instead of the running
+	// configuration files.
+	File string
+	// Partitions restricts the command to these partitions.
+	Partitions []string
+	// Verify only checks that the configuration would load, without
+	// applying it.
+	Verify bool
+}
+
+// sysConfigCommand is the body of the save and load commands of sys/config.
+type sysConfigCommand struct {
+	Command string              `json:"command"`
+	Name    string              `json:"name,omitempty"`
+	Options []map[string]string `json:"options,omitempty"`
+}
+
+func (o *SysConfigOptions) options() []map[string]string {
+	var options []map[string]string
+	if o == nil {
+		return options
+	}
+	if o.File != "" {
+		options = append(options, map[string]string{"file": o.File})
+	}
+	if len(o.Partitions) > 0 {
+		options = append(options, map[string]string{"partitions": "{ " + strings.Join(o.Partitions, " ") + " }"})
+	}
+	if o.Verify {
+		options = append(options, map[string]string{"verify": ""})
+	}
+	return options
+}
+
+// SaveSysConfig saves the running configuration, as "tmsh save sys config"
+// does. opts may be nil.
+func (b *BigIP) SaveSysConfig(opts *SysConfigOptions) error {
+	return b.post(&sysConfigCommand{Command: "save", Options: opts.options()}, uriSys, uriConfig)
+}
+
+// LoadSysConfig replaces the running configuration with the saved one, or
+// with opts.File, as "tmsh load sys config" does. opts may be nil.
+func (b *BigIP) LoadSysConfig(opts *SysConfigOptions) error {
+	return b.post(&sysConfigCommand{Command: "load", Options: opts.options()}, uriSys, uriConfig)
+}
+
+// MergeSysConfig merges the configuration in opts.File, which is required,
+// into the running configuration, as "tmsh load sys config file <file>
+// merge" does.
+func (b *BigIP) MergeSysConfig(opts *SysConfigOptions) error {
+	if opts == nil || opts.File == "" {
+		return errors.New("a file is required to merge a configuration")
+	}
+	return b.post(&sysConfigCommand{Command: "load", Name: "merge", Options: opts.options()}, uriSys, uriConfig)
+}
+
+// CliScript is a tmsh script (cli/script).
+type CliScript struct {
+	Name        string `json:"name,omitempty"`
+	Partition   string `json:"partition,omitempty"`
+	FullPath    string `json:"fullPath,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Script is the Tcl source of the script.
+	Script string `json:"apiAnonymous,omitempty"`
+}
+
+func (b *BigIP) cliScripts() *Resource[CliScript] {
+	return NewResource[CliScript](b, uriCli, uriScript)
+}
+
+// CliScripts returns every tmsh script.
+func (b *BigIP) CliScripts() ([]CliScript, error) {
+	return b.cliScripts().List()
+}
+
+// GetCliScript returns the tmsh script called name.
+func (b *BigIP) GetCliScript(name string) (*CliScript, error) {
+	return b.cliScripts().Get(name)
+}
+
+// CreateCliScript adds a tmsh script.
+func (b *BigIP) CreateCliScript(config *CliScript) error {
+	return b.cliScripts().Create(config)
+}
+
+// ModifyCliScript replaces the tmsh script called name.
+func (b *BigIP) ModifyCliScript(name string, config *CliScript) error {
+	return b.cliScripts().Update(name, config)
+}
+
+// DeleteCliScript removes the tmsh script called name.
+func (b *BigIP) DeleteCliScript(name string) error {
+	return b.cliScripts().Delete(name)
+}
+
+// RunCliScript runs the tmsh script called name with args and returns its
+// output. Errors raised by the script are returned as an *APIError.
+func (b *BigIP) RunCliScript(name string, args ...string) (string, error) {
+	return b.RunCliScriptContext(b.context(), name, args...)
+}
+
+// RunCliScriptContext is the context-aware variant of RunCliScript. The
+// request is abandoned once ctx is done.
+func (b *BigIP) RunCliScriptContext(ctx context.Context, name string, args ...string) (string, error) {
+	cmd := &BigipCommand{Command: "run", UtilCmdArgs: strings.Join(args, " ")}
+	body, err := jsonMarshal(struct {
+		*BigipCommand
+		Name string `json:"name"`
+	}{cmd, name})
+	if err != nil {
+		return "", err
+	}
+	resp, err := b.APICallContext(ctx, &APIRequest{
+		Method:      "post",
+		URL:         b.iControlPath([]string{uriCli, uriScript}),
+		Body:        string(body),
+		ContentType: "application/json",
+	})
+	if err != nil {
+		return "", err
+	}
+	var result BigipCommand
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return "", err
+	}
+	return result.CommandResult, nil
+}
+
+// UnixLs returns the names of the entries of the directory dir on the device.
+func (b *BigIP) UnixLs(dir string) ([]string, error) {
+	out, err := b.runUtil(b.context(), uriUnixLs, dir)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(out, "cannot access") || strings.Contains(out, "cannot open") {
+		return nil, fmt.Errorf("unix-ls %s: %s", dir, strings.TrimSpace(out))
+	}
+	var entries []string
+	for _, line := range strings.Split(out, "\n") {
+		if line != "" {
+			entries = append(entries, line)
+		}
+	}
+	return entries, nil
+}
+
+// Qkview generates a qkview diagnostic file called name in /var/tmp and
+// returns its path. Fetch it with Download from
+// "mgmt/cm/autodeploy/qkview-downloads/<name>". Generating a qkview takes
+// minutes, well beyond the default APICallTimeout.
+func (b *BigIP) Qkview(name string) (string, error) {
+	path := "/var/tmp/" + name
+	if err := checkShellPath(path); err != nil {
+		return "", err
+	}
+	// util/qkview does not report the exit status of qkview, so it is run
+	// through bash, keeping the end of its output for the error.
+	out, status, err := b.runBashStatus(b.context(), "qkview -f '"+path+"' 2>&1 | tail -n 5")
+	if err != nil {
+		return "", err
+	}
+	if status != 0 {
+		return "", fmt.Errorf("qkview exited with status %d: %s", status, strings.TrimSpace(out))
+	}
+	return path, nil
+}
+
+// exitStatusMarker prefixes the exit status runBashStatus appends to the
+// output of a script.
+const exitStatusMarker = "exit-status="
+
+// runBashStatus runs script as runBash does and returns its output and the
+// exit status of its first command, which may be piped into others.
+func (b *BigIP) runBashStatus(ctx context.Context, script string) (string, int, error) {
+	out, err := b.runBash(ctx, script+"; echo "+exitStatusMarker+"${PIPESTATUS[0]}")
+	if err != nil {
+		return "", 0, err
+	}
+	i := strings.LastIndex(out, exitStatusMarker)
+	if i < 0 {
+		return "", 0, fmt.Errorf("no exit status in the output of %q: %s", script, strings.TrimSpace(out))
+	}
+	status, err := strconv.Atoi(strings.TrimSpace(out[i+len(exitStatusMarker):]))
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid exit status in the output of %q: %w", script, err)
+	}
+	return out[:i], status, nil
+}
+
+// Dig resolves name with the device resolver, or with server when it is
+// set, and returns the records of type recordType, such as "A", as dig
+// +short reports them.
+func (b *BigIP) Dig(name, recordType, server string) ([]string, error) {
+	args := []string{"+short", name, recordType}
+	if server != "" {
+		args = append([]string{"@" + server}, args...)
+	}
+	out, err := b.runUtil(b.context(), uriDig, strings.Join(args, " "))
+	if err != nil {
+		return nil, err
+	}
+	// dig reports failures as comments, such as ";; connection timed out".
+	var records []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, ";;") || strings.HasPrefix(line, "dig:") {
+			return nil, fmt.Errorf("dig %s: %s", name, strings.TrimLeft(line, "; "))
+		}
+		if line != "" {
+			records = append(records, line)
+		}
+	}
+	return records, nil
+}
+
+// PingResult is the outcome of Ping. The round-trip times are in
+// milliseconds, and zero when no reply was received.
+type PingResult struct {
+	Transmitted, Received  int
+	PacketLoss             float64
+	MinRTT, AvgRTT, MaxRTT float64
+	// Output is the output of ping.
+	Output string
+}
+
+var (
+	pingSummary = regexp.MustCompile(`(\d+) packets transmitted, (\d+) received.*?([\d.]+)% packet loss`)
+	pingRTT     = regexp.MustCompile(`= ([\d.]+)/([\d.]+)/([\d.]+)`)
+)
+
+// Ping sends count ICMP echo requests to host from the device. Hosts that do
+// not answer are not an error; check Received.
+func (b *BigIP) Ping(host string, count int) (*PingResult, error) {
+	if count <= 0 {
+		count = 1
+	}
+	out, err := b.runUtil(b.context(), uriPing, fmt.Sprintf("-c %d %s", count, host))
+	if err != nil {
+		return nil, err
+	}
+	m := pingSummary.FindStringSubmatch(out)
+	if m == nil {
+		return nil, fmt.Errorf("ping %s: %s", host, strings.TrimSpace(out))
+	}
+	result := &PingResult{Output: out}
+	result.Transmitted, _ = strconv.Atoi(m[1])
+	result.Received, _ = strconv.Atoi(m[2])
+	result.PacketLoss, _ = strconv.ParseFloat(m[3], 64)
+	if m := pingRTT.FindStringSubmatch(out); m != nil {
+		result.MinRTT, _ = strconv.ParseFloat(m[1], 64)
+		result.AvgRTT, _ = strconv.ParseFloat(m[2], 64)
+		result.MaxRTT, _ = strconv.ParseFloat(m[3], 64)
+	}
+	return result, nil
+}
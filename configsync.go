@@ -0,0 +1,235 @@
+package bigip
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	uriSyncStatus = "sync-status"
+
+	// SyncToGroup pushes the configuration of the device to the device group.
+	SyncToGroup = "to-group"
+	// SyncFromGroup pulls the configuration of the device group to the device.
+	SyncFromGroup = "from-group"
+)
+
+// SyncStatus is the config sync status of the device, as reported by
+// "tmsh show cm sync-status".
+type SyncStatus struct {
+	// Status is for instance "In Sync", "Changes Pending", "Awaiting Initial
+	// Sync", "Sync Failure", "Disconnected" or "Standalone".
+	Status string
+	// Color is "green" when in sync, "yellow" or "red" otherwise.
+	Color   string
+	Mode    string
+	Summary string
+	// Details lists the status of each device and device group, such as
+	// "failover-group (In Sync): All devices in the device group are in sync".
+	Details []string
+}
+
+// InSync reports whether the device is in sync with its device groups. A
+// standalone device is not.
+func (s *SyncStatus) InSync() bool {
+	return s.Status == "In Sync"
+}
+
+// Failed reports whether the status cannot become "In Sync" without action,
+// as when the sync failed, a peer is disconnected or the device is
+// standalone.
+func (s *SyncStatus) Failed() bool {
+	return syncFailed(s.Status)
+}
+
+func syncFailed(status string) bool {
+	switch status {
+	case "Sync Failure", "Disconnected", "Standalone":
+		return true
+	}
+	return false
+}
+
+// DeviceGroup returns the status and summary the details give for
+// deviceGroup, such as "In Sync" and "All devices in the device group are in
+// sync". ok is false when the details do not mention it.
+func (s *SyncStatus) DeviceGroup(deviceGroup string) (status, summary string, ok bool) {
+	prefix := deviceGroup + " ("
+	for _, detail := range s.Details {
+		if !strings.HasPrefix(detail, prefix) {
+			continue
+		}
+		rest := detail[len(prefix):]
+		end := strings.Index(rest, ")")
+		if end < 0 {
+			continue
+		}
+		return rest[:end], strings.TrimSpace(strings.TrimPrefix(rest[end+1:], ":")), true
+	}
+	return "", "", false
+}
+
+// DeviceGroupSyncStatus is the config sync status of a device group.
+type DeviceGroupSyncStatus struct {
+	Devicegroup *Devicegroup
+	// Status is the status of the group, as for SyncStatus.
+	Status  string
+	Summary string
+	// Devices are the members of the group.
+	Devices []Device
+}
+
+// InSync reports whether every device of the group is in sync.
+func (s *DeviceGroupSyncStatus) InSync() bool {
+	return s.Status == "In Sync"
+}
+
+// Failed reports whether the status of the group cannot become "In Sync"
+// without action.
+func (s *DeviceGroupSyncStatus) Failed() bool {
+	return syncFailed(s.Status)
+}
+
+// SaveConfig saves the running configuration of partitions, or of every
+// partition when none is given, as "tmsh save sys config" does.
+func (b *BigIP) SaveConfig(partitions ...string) error {
+	return b.SaveSysConfig(&SysConfigOptions{Partitions: partitions})
+}
+
+// ConfigSync synchronizes the configuration between the device and
+// deviceGroup in direction, SyncToGroup or SyncFromGroup, running "tmsh run
+// cm config-sync" with RunCommand. The sync completes asynchronously; use
+// WaitForSync to wait for it.
+func (b *BigIP) ConfigSync(deviceGroup, direction string) error {
+	if direction != SyncToGroup && direction != SyncFromGroup {
+		return fmt.Errorf("invalid config sync direction %q", direction)
+	}
+	if err := checkShellPath(deviceGroup); err != nil {
+		return err
+	}
+	if _, err := b.Devicegroups(deviceGroup); err != nil {
+		return fmt.Errorf("device group %s: %w", deviceGroup, err)
+	}
+	result, err := b.RunCommand(&BigipCommand{
+		Command:     "run",
+		UtilCmdArgs: fmt.Sprintf("-c \"tmsh run cm config-sync %s %s\"", direction, deviceGroup),
+	})
+	if err != nil {
+		return err
+	}
+	// tmsh prints nothing unless the sync could not be started.
+	if out := strings.TrimSpace(result.CommandResult); out != "" {
+		return fmt.Errorf("config sync %s %s failed: %s", direction, deviceGroup, out)
+	}
+	return nil
+}
+
+// SyncStatus returns the config sync status of the device.
+func (b *BigIP) SyncStatus() (*SyncStatus, error) {
+	stats, err := b.getObjectStats(uriCm, uriSyncStatus)
+	if err != nil {
+		return nil, err
+	}
+	status := &SyncStatus{
+		Status:  stats.Descriptions["status"],
+		Color:   stats.Descriptions["color"],
+		Mode:    stats.Descriptions["mode"],
+		Summary: stats.Descriptions["summary"],
+	}
+	// The details are numbered in the order tmsh shows them.
+	details := stats.Nested["details"]
+	keys := make([]string, 0, len(details))
+	for k := range details {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, _ := strconv.Atoi(keys[i])
+		b, _ := strconv.Atoi(keys[j])
+		return a < b
+	})
+	for _, k := range keys {
+		status.Details = append(status.Details, details[k].Descriptions["details"])
+	}
+	return status, nil
+}
+
+// DeviceGroupSyncStatus returns the config sync status of deviceGroup, with
+// the devices in it.
+func (b *BigIP) DeviceGroupSyncStatus(deviceGroup string) (*DeviceGroupSyncStatus, error) {
+	group, err := b.Devicegroups(deviceGroup)
+	if err != nil {
+		return nil, err
+	}
+	var members Devicerecords
+	if err, _ := b.getForEntity(&members, uriCm, uriDG, deviceGroup, uriDevices); err != nil {
+		return nil, err
+	}
+	devices, err := b.GetDevices()
+	if err != nil {
+		return nil, err
+	}
+	sync, err := b.SyncStatus()
+	if err != nil {
+		return nil, err
+	}
+	status := &DeviceGroupSyncStatus{Devicegroup: group}
+	var ok bool
+	if status.Status, status.Summary, ok = sync.DeviceGroup(group.Name); !ok {
+		return nil, fmt.Errorf("no config sync status for device group %s", deviceGroup)
+	}
+	for _, member := range members.Items {
+		for _, device := range devices {
+			if device.Name == member.Name {
+				status.Devices = append(status.Devices, device)
+			}
+		}
+	}
+	return status, nil
+}
+
+// WaitForSync polls the config sync status until the device, or each of
+// deviceGroups when some are given, is in sync, and returns the status. It
+// fails as soon as the status cannot become in sync without action, such as
+// "Sync Failure", "Disconnected" or "Standalone", and gives up when ctx is
+// done.
+func (b *BigIP) WaitForSync(ctx context.Context, deviceGroups ...string) (*SyncStatus, error) {
+	var status *SyncStatus
+	client := b.WithContext(ctx)
+	for _, group := range deviceGroups {
+		if _, err := client.Devicegroups(group); err != nil {
+			return nil, fmt.Errorf("device group %s: %w", group, err)
+		}
+	}
+	err := poll(ctx, statusPollInterval, func(context.Context) (bool, error) {
+		var err error
+		status, err = client.SyncStatus()
+		if err != nil {
+			return false, err
+		}
+		if len(deviceGroups) == 0 {
+			if status.Failed() {
+				return true, fmt.Errorf("config sync status is %q: %s", status.Status, status.Summary)
+			}
+			return status.InSync(), nil
+		}
+		inSync := true
+		for _, group := range deviceGroups {
+			groupStatus, summary, ok := status.DeviceGroup(group)
+			if ok && syncFailed(groupStatus) {
+				return true, fmt.Errorf("config sync status of device group %s is %q: %s", group, groupStatus, summary)
+			}
+			inSync = inSync && groupStatus == "In Sync"
+		}
+		return inSync, nil
+	})
+	if err != nil {
+		if status != nil && ctx.Err() != nil {
+			return status, fmt.Errorf("config sync status is %q: %w", status.Status, err)
+		}
+		return status, err
+	}
+	return status, nil
+}
@@ -0,0 +1,330 @@
+package bigip
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type DoTestSuite struct {
+	suite.Suite
+	Client       *BigIP
+	Server       *httptest.Server
+	Requests     []string
+	ResponseFunc func(http.ResponseWriter, *http.Request)
+	mu           sync.Mutex
+}
+
+func (s *DoTestSuite) SetupSuite() {
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		s.mu.Lock()
+		s.Requests = append(s.Requests, strings.TrimSpace(r.Method+" "+r.URL.RequestURI()+" "+string(body)))
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if s.ResponseFunc != nil {
+			s.ResponseFunc(w, r)
+		}
+	}))
+	s.Client = NewSession(&Config{
+		Address:           s.Server.URL,
+		CertVerifyDisable: true,
+		ConfigOptions: &ConfigOptions{
+			APICallTimeout: 5 * time.Second,
+			RetryPolicy:    &ExponentialBackoff{MaxAttempts: 1, BaseDelay: time.Millisecond},
+		},
+	})
+}
+
+func (s *DoTestSuite) TearDownSuite() {
+	s.Server.Close()
+}
+
+func (s *DoTestSuite) SetupTest() {
+	shortenPollInterval(s.T(), &doPollInterval)
+	shortenPollInterval(s.T(), &readyPollInterval)
+	s.ResponseFunc = nil
+	s.Requests = nil
+}
+
+func TestDoSuite(t *testing.T) {
+	suite.Run(t, new(DoTestSuite))
+}
+
+const doDeclaration = `{
+	"schemaVersion": "1.39.0",
+	"class": "Device",
+	"async": true,
+	"Common": {
+		"class": "Tenant",
+		"mySystem": {"class": "System", "hostname": "bigip1.example.com", "autoPhonehome": false},
+		"myDns": {"class": "DNS", "nameServers": ["192.0.2.53"], "search": ["example.com"]},
+		"myNtp": {"class": "NTP", "servers": ["0.pool.ntp.org"], "timezone": "UTC"},
+		"myLicense": {"class": "License", "licenseType": "regKey", "regKey": "AAAAA-BBBBB-CCCCC-DDDDD-EEEEEEE"},
+		"myProvisioning": {"class": "Provision", "ltm": "nominal", "asm": "minimum"},
+		"external": {"class": "VLAN", "tag": 4094, "mtu": 1500, "interfaces": [{"name": "1.1", "tagged": true}]},
+		"external-self": {"class": "SelfIp", "address": "192.0.2.20/24", "vlan": "external", "allowService": "default"},
+		"default": {"class": "Route", "gw": "192.0.2.1", "network": "default"}
+	}
+}`
+
+const doReady = `{"entries":{"https://localhost/mgmt/tm/sys/ready/0":{"nestedStats":{"entries":{"configReady":{"description":"yes"},"licenseReady":{"description":"yes"},"provisionReady":{"description":"yes"}}}}}}`
+
+func (s *DoTestSuite) TestPostDo() {
+	polls := 0
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mgmt/shared/declarative-onboarding":
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"id":"d1","selfLink":"https://localhost/mgmt/shared/declarative-onboarding/task/d1","result":{"class":"Result","code":202,"status":"RUNNING","message":"processing"}}`))
+		case "/mgmt/shared/declarative-onboarding/task/d1":
+			polls++
+			switch polls {
+			case 1:
+				w.Write([]byte(`{"id":"d1","result":{"class":"Result","code":202,"status":"REBOOTING_AND_RESUMING","message":"reboot required"}}`))
+			case 2:
+				// DO does not answer while the device restarts.
+				w.WriteHeader(http.StatusServiceUnavailable)
+			default:
+				w.Write([]byte(`{"id":"d1","result":{"class":"Result","code":200,"status":"OK","message":"success"},"declaration":{"class":"Device"}}`))
+			}
+		}
+	}
+
+	task, err := s.Client.PostDo(doDeclaration)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "d1", task.ID)
+	assert.Equal(s.T(), DoResult{Class: "Result", Code: 200, Status: DoStatusOK, Message: "success"}, task.Result)
+	assert.JSONEq(s.T(), `{"class":"Device"}`, string(task.Declaration))
+	assert.True(s.T(), strings.HasPrefix(s.Requests[0], "POST /mgmt/shared/declarative-onboarding {"))
+	assert.Equal(s.T(), []string{
+		"GET /mgmt/shared/declarative-onboarding/task/d1",
+		"GET /mgmt/shared/declarative-onboarding/task/d1",
+		"GET /mgmt/shared/declarative-onboarding/task/d1",
+	}, s.Requests[1:])
+}
+
+func (s *DoTestSuite) TestPostDoFailed() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mgmt/shared/declarative-onboarding":
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"id":"d2","result":{"code":202,"status":"RUNNING","message":"processing"}}`))
+		default:
+			w.Write([]byte(`{"id":"d2","result":{"code":422,"status":"ERROR","message":"bad declaration","errors":["/Common/external: should have required property 'interfaces'"]}}`))
+		}
+	}
+
+	task, err := s.Client.PostDo(doDeclaration)
+	var doErr *DoError
+	assert.True(s.T(), errors.As(err, &doErr))
+	assert.Equal(s.T(), task, doErr.Task)
+	assert.Equal(s.T(), "DO task d2 failed: ERROR 422 bad declaration (/Common/external: should have required property 'interfaces')", err.Error())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = s.Client.WaitForDoTask(ctx, "d2")
+	assert.True(s.T(), errors.Is(err, context.Canceled), "unexpected error: %v", err)
+}
+
+func (s *DoTestSuite) TestWaitForDoTaskErrors() {
+	polls := 0
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":404,"message":"task not found"}`))
+	}
+
+	_, err := s.Client.WaitForDoTask(context.Background(), "unknown")
+	var apiErr *APIError
+	assert.True(s.T(), errors.As(err, &apiErr), "unexpected error: %v", err)
+	assert.Equal(s.T(), http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(s.T(), 1, polls)
+
+	// While the device restarts, a 404 may come from a service that is not
+	// up yet, and is retried.
+	polls = 0
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		switch polls {
+		case 1:
+			w.Write([]byte(`{"id":"d3","result":{"code":202,"status":"REBOOTING","message":"reboot required"}}`))
+		case 2:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"task not found"}`))
+		default:
+			w.Write([]byte(`{"id":"d3","result":{"code":200,"status":"OK","message":"success"}}`))
+		}
+	}
+
+	task, err := s.Client.WaitForDoTask(context.Background(), "d3")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), DoStatusOK, task.Result.Status)
+	assert.Equal(s.T(), 3, polls)
+}
+
+func (s *DoTestSuite) TestPostDoValidates() {
+	s.Client.DoValidator = NewDoValidator()
+	defer func() { s.Client.DoValidator = nil }()
+
+	_, err := s.Client.PostDo(`{"class":"Device","schemaVersion":"1.39.0","Common":{"class":"Tenant","external":{"class":"VLAN"}}}`)
+	var invalid *SchemaError
+	assert.True(s.T(), errors.As(err, &invalid))
+	assert.Empty(s.T(), s.Requests)
+}
+
+func (s *DoTestSuite) TestGetDo() {
+	body := `{"id":"d1","result":{"code":200,"status":"OK","message":"success"},"declaration":` + doDeclaration + `}`
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}
+
+	declaration, err := s.Client.GetDo()
+	assert.Nil(s.T(), err)
+	assert.JSONEq(s.T(), doDeclaration, declaration)
+	assert.Equal(s.T(), []string{"GET /mgmt/shared/declarative-onboarding"}, s.Requests)
+
+	body = `{"id":"d1","result":{"code":200,"status":"OK"}}`
+	declaration, err = s.Client.GetDo()
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "", declaration)
+}
+
+func (s *DoTestSuite) TestGetDoInfo() {
+	body := `[{"id":0,"selfLink":"https://localhost/mgmt/shared/declarative-onboarding/info","result":{"class":"Result","code":200,"status":"OK","message":""},"version":"1.39.0","release":"4","schemaCurrent":"1.39.0","schemaMinimum":"1.0.0"}]`
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}
+
+	info, err := s.Client.GetDoInfo()
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), &DoInfo{Version: "1.39.0", Release: "4", SchemaCurrent: "1.39.0", SchemaMinimum: "1.0.0"}, info)
+	assert.Equal(s.T(), []string{"GET /mgmt/shared/declarative-onboarding/info"}, s.Requests)
+
+	body = `{"version":"1.21.0","release":"3","schemaCurrent":"1.21.0","schemaMinimum":"1.0.0"}`
+	info, err = s.Client.GetDoInfo()
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "1.21.0", info.Version)
+
+	body = `[]`
+	_, err = s.Client.GetDoInfo()
+	assert.NotNil(s.T(), err)
+}
+
+func (s *DoTestSuite) TestWaitForReboot() {
+	polls := 0
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mgmt/shared/declarative-onboarding":
+			polls++
+			switch polls {
+			case 1:
+				w.Write([]byte(`{"id":"d1","result":{"code":202,"status":"REBOOTING","message":"reboot required"}}`))
+			case 2:
+				w.WriteHeader(http.StatusNotFound)
+			default:
+				w.Write([]byte(`{"id":"d1","result":{"code":202,"status":"RUNNING","message":"processing"}}`))
+			}
+		case "/mgmt/tm/sys/ready":
+			w.Write([]byte(doReady))
+		}
+	}
+
+	assert.Nil(s.T(), s.Client.WaitForReboot(context.Background()))
+	assert.Equal(s.T(), []string{
+		"GET /mgmt/shared/declarative-onboarding",
+		"GET /mgmt/shared/declarative-onboarding",
+		"GET /mgmt/shared/declarative-onboarding",
+		"GET /mgmt/tm/sys/ready",
+	}, s.Requests)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := s.Client.WaitForReboot(ctx)
+	assert.True(s.T(), errors.Is(err, context.Canceled), "unexpected error: %v", err)
+}
+
+func TestDoValidatorBuiltIn(t *testing.T) {
+	v := NewDoValidator()
+	assert.Nil(t, v.Validate(doDeclaration))
+	assert.Nil(t, v.Validate(`{"class":"DO","declaration":`+doDeclaration+`}`))
+
+	err := v.Validate(`{"class":"DO","declaration":{"class":"Device","schemaVersion":"1.39.0","Common":{"class":"Tenant",
+		"myLicense":{"class":"License","licenseType":"regKey"},
+		"myProvisioning":{"class":"Provision","ltm":"full"},
+		"external":{"class":"VLAN","tag":5000},
+		"self":{"class":"SelfIp","address":"192.0.2.20/24"}}}}`)
+	var invalid *SchemaError
+	assert.True(t, errors.As(err, &invalid))
+	assert.Equal(t, []SchemaProblem{
+		{"/declaration/Common/external", "missing property 'interfaces'"},
+		{"/declaration/Common/external/tag", "maximum: got 5,000, want 4,094"},
+		{"/declaration/Common/myLicense", "missing property 'regKey'"},
+		{"/declaration/Common/myProvisioning/ltm", "value must be one of 'none', 'minimum', 'nominal', 'dedicated'"},
+		{"/declaration/Common/self", "missing property 'vlan'"},
+	}, invalid.Problems)
+
+	err = v.Validate(`{"class":"DO"}`)
+	assert.Equal(t, "DO declaration is invalid: /: missing property 'declaration'", err.Error())
+}
+
+func TestDoValidatorSchemas(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "schema", "1.39.0")
+	assert.Nil(t, os.MkdirAll(dir, 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "base.schema.json"), []byte(`{
+		"$id": "https://example.com/schema/1.39.0/base.schema.json",
+		"type": "object",
+		"required": ["class", "schemaVersion"],
+		"properties": {
+			"class": {"const": "Device"},
+			"schemaVersion": {"enum": ["1.39.0", "1.38.0"]},
+			"Common": {
+				"type": "object",
+				"additionalProperties": {"oneOf": [{"type": "string"}, {"$ref": "system.schema.json#/definitions/system"}]}
+			}
+		}
+	}`), 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "system.schema.json"), []byte(`{
+		"$id": "https://example.com/schema/1.39.0/system.schema.json",
+		"definitions": {
+			"system": {
+				"type": "object",
+				"required": ["class"],
+				"properties": {"class": {"const": "System"}, "hostname": {"type": "string", "maxLength": 63}},
+				"additionalProperties": false
+			}
+		}
+	}`), 0o644))
+
+	v, err := LoadDoValidator(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.39.0"}, v.Versions())
+
+	assert.Nil(t, v.Validate(`{"class":"Device","schemaVersion":"1.38.0","Common":{"class":"Tenant","mySystem":{"class":"System","hostname":"bigip1"}}}`))
+	err = v.Validate(`{"class":"Device","schemaVersion":"1.38.0","Common":{"mySystem":{"class":"System","hostname":"bigip1","dns":true}}}`)
+	var invalid *SchemaError
+	assert.True(t, errors.As(err, &invalid))
+	assert.Equal(t, "1.39.0", invalid.SchemaVersion)
+	assert.Contains(t, err.Error(), "/Common/mySystem: 'oneOf' failed, none matched")
+
+	assert.Equal(t, `no DO schema supports schemaVersion "1.40.0", have 1.39.0`,
+		v.Validate(`{"class":"Device","schemaVersion":"1.40.0"}`).Error())
+	assert.Equal(t, `missing or invalid DO schemaVersion ""`,
+		v.Validate(`{"class":"DO","declaration":{"class":"Device","Common":{"class":"Tenant"}}}`).Error())
+
+	_, err = LoadDoValidator(t.TempDir())
+	assert.NotNil(t, err)
+}
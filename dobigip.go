@@ -0,0 +1,237 @@
+package bigip
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const uriDeclarativeOnboarding = "declarative-onboarding"
+
+// Status of a DO task.
+const (
+	DoStatusOK                   = "OK"
+	DoStatusError                = "ERROR"
+	DoStatusRunning              = "RUNNING"
+	DoStatusRollingBack          = "ROLLING_BACK"
+	DoStatusRebooting            = "REBOOTING"
+	DoStatusRebootingAndResuming = "REBOOTING_AND_RESUMING"
+)
+
+// doPollInterval is the delay between two checks of a DO task, or of the
+// device while DO restarts it.
+var doPollInterval = 5 * time.Second
+
+// DoInfo describes the DO extension installed on the device.
+type DoInfo struct {
+	Version string `json:"version"`
+	Release string `json:"release"`
+	// SchemaCurrent and SchemaMinimum bound the schemaVersion of the
+	// declarations DO accepts.
+	SchemaCurrent string `json:"schemaCurrent"`
+	SchemaMinimum string `json:"schemaMinimum"`
+}
+
+// DoTask is a DO task, which processes a declaration.
+type DoTask struct {
+	ID     string   `json:"id"`
+	Result DoResult `json:"result"`
+	// Declaration is the declaration processed.
+	Declaration json.RawMessage `json:"declaration,omitempty"`
+}
+
+// DoResult is the state of a DO task.
+type DoResult struct {
+	Class string `json:"class,omitempty"`
+	Code  int64  `json:"code"`
+	// Status is one of the DoStatus constants.
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	// Errors details why the declaration failed.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Succeeded reports whether the declaration was applied.
+func (r *DoResult) Succeeded() bool {
+	return r.Status == DoStatusOK
+}
+
+// pending reports whether DO is still processing the declaration.
+func (r *DoResult) pending() bool {
+	switch r.Status {
+	case DoStatusOK, DoStatusError:
+		return false
+	case "":
+		return r.Code == 0 || r.Code == 202
+	}
+	return true
+}
+
+// rebooting reports whether DO is restarting the device.
+func (r *DoResult) rebooting() bool {
+	return r.Status == DoStatusRebooting || r.Status == DoStatusRebootingAndResuming
+}
+
+func (r *DoResult) String() string {
+	s := fmt.Sprintf("%s %d %s", r.Status, r.Code, r.Message)
+	if len(r.Errors) > 0 {
+		s += " (" + strings.Join(r.Errors, "; ") + ")"
+	}
+	return strings.TrimSpace(s)
+}
+
+// DoError reports a DO task that failed to apply its declaration.
+type DoError struct {
+	Task *DoTask
+}
+
+func (e *DoError) Error() string {
+	return fmt.Sprintf("DO task %s failed: %s", e.Task.ID, e.Task.Result.String())
+}
+
+// err returns a *DoError if the task failed.
+func (t *DoTask) err() error {
+	if t.Result.Succeeded() {
+		return nil
+	}
+	return &DoError{Task: t}
+}
+
+// PostDo posts the DO declaration doJson and waits for DO to apply it.
+func (b *BigIP) PostDo(doJson string) (*DoTask, error) {
+	return b.PostDoContext(b.context(), doJson)
+}
+
+// PostDoContext is the context-aware variant of PostDo. The declaration is
+// checked by b.DoValidator first, if set. The task is returned along with
+// any error, such as a *DoError, once it has completed, which may take a
+// restart of the device; waiting stops once ctx is done.
+func (b *BigIP) PostDoContext(ctx context.Context, doJson string) (*DoTask, error) {
+	b = b.WithContext(ctx)
+	if b.DoValidator != nil {
+		if err := b.DoValidator.Validate(doJson); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := b.postAS3Req(doJson, uriMgmt, uriShared, uriDeclarativeOnboarding)
+	if err != nil {
+		return nil, err
+	}
+	var task DoTask
+	if err := json.Unmarshal(resp, &task); err != nil {
+		return nil, fmt.Errorf("invalid DO response: %w", err)
+	}
+	if !task.Result.pending() {
+		return &task, task.err()
+	}
+	if task.ID == "" {
+		return nil, fmt.Errorf("DO started no task: %s", strings.TrimSpace(string(resp)))
+	}
+	b.log().Debug("DO task started", "task_id", task.ID)
+	return b.WaitForDoTask(ctx, task.ID)
+}
+
+// GetDo returns the declaration DO applied last, or "" if there is none.
+func (b *BigIP) GetDo() (string, error) {
+	var task DoTask
+	err, _ := b.getForEntity(&task, uriMgmt, uriShared, uriDeclarativeOnboarding)
+	if err != nil {
+		return "", err
+	}
+	if len(task.Declaration) == 0 || string(task.Declaration) == "null" {
+		return "", nil
+	}
+	return string(task.Declaration), nil
+}
+
+// GetDoTask returns the DO task id.
+func (b *BigIP) GetDoTask(id string) (*DoTask, error) {
+	var task DoTask
+	err, _ := b.getForEntity(&task, uriMgmt, uriShared, uriDeclarativeOnboarding, uriTask, id)
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// GetDoInfo returns the version of the DO extension installed on the device.
+func (b *BigIP) GetDoInfo() (*DoInfo, error) {
+	resp, err := b.APICall(&APIRequest{
+		Method:      "get",
+		URL:         b.iControlPath([]string{uriMgmt, uriShared, uriDeclarativeOnboarding, uriInfo}),
+		ContentType: "application/json",
+	})
+	if err != nil {
+		return nil, err
+	}
+	// DO answers with a list holding the info of the device, or of each
+	// device it onboards remotely.
+	var infos []DoInfo
+	if resp = bytes.TrimSpace(resp); len(resp) > 0 && resp[0] != '[' {
+		resp = append(append([]byte{'['}, resp...), ']')
+	}
+	if err := json.Unmarshal(resp, &infos); err != nil {
+		return nil, err
+	}
+	if len(infos) == 0 || infos[0].Version == "" {
+		return nil, fmt.Errorf("DO not installed or not ready")
+	}
+	return &infos[0], nil
+}
+
+// WaitForDoTask polls the DO task id until it completes, and returns it. The
+// error is a *DoError if the declaration failed. Transport and server errors
+// are retried until ctx is done. Client errors, such as a 404 for an unknown
+// task, fail at once, unless DO last reported that it restarts the device,
+// since it does not answer reliably until the device is back.
+func (b *BigIP) WaitForDoTask(ctx context.Context, id string) (*DoTask, error) {
+	client := b.WithContext(ctx)
+	var task *DoTask
+	status := ""
+	err := poll(ctx, doPollInterval, func(context.Context) (bool, error) {
+		t, err := client.GetDoTask(id)
+		if err != nil {
+			rebooting := task != nil && task.Result.rebooting()
+			return !rebooting && !isTransient(err), err
+		}
+		if t.Result.Status != status {
+			status = t.Result.Status
+			b.log().Debug("DO task status", "task_id", id, "status", status, "message", t.Result.Message)
+		}
+		task = t
+		return !t.Result.pending(), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DO task %s: %w", id, err)
+	}
+	if !task.Result.Succeeded() {
+		b.log().Error("DO task failed", "task_id", id, "status", task.Result.Status, "code", task.Result.Code, "message", task.Result.Message)
+	}
+	return task, task.err()
+}
+
+// WaitForReboot waits for a restart of the device by DO to complete: until
+// DO answers again and no longer reports that it is restarting the device,
+// and the device is ready. DO keeps processing the declaration afterwards,
+// which WaitForDoTask waits for. Errors are retried until ctx is done.
+func (b *BigIP) WaitForReboot(ctx context.Context) error {
+	client := b.WithContext(ctx)
+	err := poll(ctx, doPollInterval, func(ctx context.Context) (bool, error) {
+		var task DoTask
+		if err, _ := client.getForEntity(&task, uriMgmt, uriShared, uriDeclarativeOnboarding); err != nil {
+			return false, err
+		}
+		if task.Result.rebooting() {
+			b.log().Debug("DO restarting the device", "task_id", task.ID)
+			return false, nil
+		}
+		return b.ready(ctx, "")
+	})
+	if err != nil {
+		return fmt.Errorf("device not back from DO reboot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package bigip
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// doCoreSchema is the schema used when no DO schema was supplied. It checks
+// the Device declaration, the Common tenant and the required properties of
+// the classes used for day-0 onboarding.
+const doCoreSchema = `{
+	"type": "object",
+	"required": ["class", "schemaVersion"],
+	"properties": {
+		"class": {"const": "Device"},
+		"schemaVersion": {"type": "string", "pattern": "^[0-9]+\\.[0-9]+\\.[0-9]+$"},
+		"async": {"type": "boolean"},
+		"label": {"type": "string"},
+		"Common": {
+			"type": "object",
+			"required": ["class"],
+			"properties": {
+				"class": {"const": "Tenant"},
+				"hostname": {"type": "string"}
+			},
+			"additionalProperties": {"$ref": "#/definitions/child"}
+		}
+	},
+	"definitions": {
+		"child": {
+			"if": {"type": "object"},
+			"then": {"required": ["class"], "properties": {"class": {"type": "string"}}, "allOf": [
+				{"if": {"required": ["class"], "properties": {"class": {"const": "System"}}}, "then": {"$ref": "#/definitions/System"}},
+				{"if": {"required": ["class"], "properties": {"class": {"const": "DNS"}}}, "then": {"$ref": "#/definitions/DNS"}},
+				{"if": {"required": ["class"], "properties": {"class": {"const": "NTP"}}}, "then": {"$ref": "#/definitions/NTP"}},
+				{"if": {"required": ["class"], "properties": {"class": {"const": "License"}}}, "then": {"$ref": "#/definitions/License"}},
+				{"if": {"required": ["class"], "properties": {"class": {"const": "Provision"}}}, "then": {"$ref": "#/definitions/Provision"}},
+				{"if": {"required": ["class"], "properties": {"class": {"const": "VLAN"}}}, "then": {"$ref": "#/definitions/VLAN"}},
+				{"if": {"required": ["class"], "properties": {"class": {"const": "SelfIp"}}}, "then": {"required": ["address", "vlan"]}},
+				{"if": {"required": ["class"], "properties": {"class": {"const": "Route"}}}, "then": {"required": ["gw"]}},
+				{"if": {"required": ["class"], "properties": {"class": {"const": "User"}}}, "then": {"required": ["userType"]}}
+			]}
+		},
+		"System": {
+			"properties": {
+				"hostname": {"type": "string"},
+				"autoCheck": {"type": "boolean"},
+				"autoPhonehome": {"type": "boolean"}
+			}
+		},
+		"DNS": {
+			"properties": {
+				"nameServers": {"type": "array", "items": {"type": "string"}},
+				"search": {"type": "array", "items": {"type": "string"}}
+			}
+		},
+		"NTP": {
+			"properties": {
+				"servers": {"type": "array", "items": {"type": "string"}},
+				"timezone": {"type": "string"}
+			}
+		},
+		"License": {
+			"required": ["licenseType"],
+			"properties": {
+				"licenseType": {"enum": ["regKey", "licensePool"]}
+			},
+			"if": {"properties": {"licenseType": {"const": "regKey"}}},
+			"then": {"required": ["regKey"]},
+			"else": {"required": ["licensePool"]}
+		},
+		"Provision": {
+			"additionalProperties": {"enum": ["none", "minimum", "nominal", "dedicated"]},
+			"properties": {"class": {"const": "Provision"}}
+		},
+		"VLAN": {
+			"required": ["interfaces"],
+			"properties": {
+				"tag": {"type": "integer", "minimum": 1, "maximum": 4094},
+				"mtu": {"type": "integer", "minimum": 576, "maximum": 9198},
+				"interfaces": {"type": "array", "items": {"type": "object", "required": ["name"]}}
+			}
+		}
+	}
+}`
+
+// DoValidator checks DO declarations against the DO JSON schema of their
+// schemaVersion, without network access.
+type DoValidator struct {
+	schemaValidator
+}
+
+// NewDoValidator returns a validator without DO schemas, which checks
+// declarations against a built-in schema of the common onboarding classes
+// until schemas are added with AddSchema.
+func NewDoValidator() *DoValidator {
+	return &DoValidator{newSchemaValidator("DO", doCoreSchema)}
+}
+
+// LoadDoValidator returns a validator with the DO schemas in dirs, each
+// holding the schema files of a DO release, such as the "src/schema/1.39.0"
+// directory of the DO sources: base.schema.json and the files it refers to.
+// The version of each release is taken from the path of its directory.
+func LoadDoValidator(dirs ...string) (*DoValidator, error) {
+	v := NewDoValidator()
+	for _, dir := range dirs {
+		versions := schemaVersionPattern.FindAllString(dir, -1)
+		if len(versions) == 0 {
+			return nil, fmt.Errorf("no DO version in the path of schemas %s", dir)
+		}
+		base, err := os.ReadFile(filepath.Join(dir, "base.schema.json"))
+		if err != nil {
+			return nil, err
+		}
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		var referenced [][]byte
+		for _, file := range files {
+			if filepath.Base(file) == "base.schema.json" {
+				continue
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			referenced = append(referenced, data)
+		}
+		if err := v.AddSchema(versions[len(versions)-1], base, referenced...); err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+	}
+	return v, nil
+}
+
+// Validate checks doJson, a Device declaration or a DO request wrapping one.
+// It uses the schema of the oldest DO version that supports the
+// schemaVersion of the declaration, or the built-in one when no schema was
+// added; once schemas are added, a missing or invalid schemaVersion is an
+// error. The error is a *SchemaError when the declaration does not match
+// the schema.
+func (v *DoValidator) Validate(doJson string) error {
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(doJson), &doc); err != nil {
+		return fmt.Errorf("invalid DO declaration: %w", err)
+	}
+	// The DO schemas describe the Device declaration, which a request of
+	// class DO wraps.
+	declaration, prefix := doc, ""
+	if doc["class"] == "DO" {
+		inner, ok := doc["declaration"].(map[string]interface{})
+		if !ok {
+			return &SchemaError{Extension: "DO", Problems: []SchemaProblem{{"", "missing property 'declaration'"}}}
+		}
+		declaration, prefix = inner, "/declaration"
+	}
+	declared, _ := declaration["schemaVersion"].(string)
+	return v.validate(declared, declaration, prefix)
+}
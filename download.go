@@ -0,0 +1,177 @@
+package bigip
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned, wrapped, when a transferred file does not
+// match the expected checksum.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// defaultDownloadChunkSize is the largest range the device serves at once.
+const defaultDownloadChunkSize = 1024 * 1024
+
+// DownloadOptions tunes Download.
+type DownloadOptions struct {
+	// ChunkSize is the number of bytes requested at a time. Defaults to 1 MiB,
+	// the most the device serves in one response.
+	ChunkSize int64
+	// Offset is the first byte to download, to resume an interrupted
+	// download. Only the bytes from Offset on are written.
+	Offset int64
+	// Progress, if set, is called after every chunk with the number of bytes
+	// of the file transferred so far, Offset included, and the file size.
+	Progress func(done, total int64)
+	// SHA256 is the expected hex digest of the whole file. It cannot be
+	// combined with Offset, since the skipped bytes are not seen; use
+	// DownloadFile to resume a download and verify it.
+	SHA256 string
+}
+
+// Download copies the file at path to w and returns the number of bytes
+// written. path is relative to the device, such as
+// "mgmt/shared/file-transfer/ucs-downloads/backup.ucs" or
+// "mgmt/cm/autodeploy/qkview-downloads/support.qkview". The file is fetched
+// in ranges, each retried according to the RetryPolicy.
+func (b *BigIP) Download(ctx context.Context, path string, w io.Writer) (int64, error) {
+	return b.DownloadWithOptions(ctx, path, w, nil)
+}
+
+// DownloadWithOptions is Download with control over chunking, resumption,
+// progress reporting and checksum verification.
+func (b *BigIP) DownloadWithOptions(ctx context.Context, path string, w io.Writer, opts *DownloadOptions) (int64, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	if opts.SHA256 == "" {
+		return b.download(ctx, path, w, opts)
+	}
+	if opts.Offset > 0 {
+		return 0, errors.New("SHA256 cannot be verified when resuming from an offset")
+	}
+	h := sha256.New()
+	n, err := b.download(ctx, path, io.MultiWriter(w, h), opts)
+	if err != nil {
+		return n, err
+	}
+	return n, verifySHA256(h, opts.SHA256)
+}
+
+// DownloadFile downloads the file at path to localPath. If localPath already
+// holds the beginning of the file, for instance after an interrupted
+// download, the download resumes where it stopped. opts.Offset is ignored;
+// opts.SHA256 is checked against the whole local file.
+func (b *BigIP) DownloadFile(ctx context.Context, path, localPath string, opts *DownloadOptions) (int64, error) {
+	o := DownloadOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	f, err := os.OpenFile(localPath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	want := o.SHA256
+	h := sha256.New()
+	// Reading the partial file through the hash leaves f positioned at its end.
+	o.Offset, err = io.Copy(h, f)
+	if err != nil {
+		return 0, err
+	}
+	n, err := b.download(ctx, path, io.MultiWriter(f, h), &o)
+	if err != nil || want == "" {
+		return n, err
+	}
+	return n, verifySHA256(h, want)
+}
+
+func verifySHA256(h hash.Hash, want string) error {
+	got := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("%w: got SHA-256 %s, want %s", ErrChecksumMismatch, got, want)
+	}
+	return nil
+}
+
+func (b *BigIP) download(ctx context.Context, path string, w io.Writer, opts *DownloadOptions) (int64, error) {
+	options := &APIRequest{
+		Method:      "get",
+		URL:         strings.TrimPrefix(path, "/"),
+		ContentType: "application/octet-stream",
+	}
+	var format string
+	if strings.Contains(options.URL, "mgmt/") {
+		format = "%s/%s"
+	} else {
+		format = "%s/mgmt/%s"
+	}
+	urlString := fmt.Sprintf(format, b.Host, options.URL)
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultDownloadChunkSize
+	}
+
+	var written int64
+	start, total := opts.Offset, int64(-1)
+	for total < 0 || start < total {
+		// When resuming, the first range starts one byte early so that it is
+		// valid even if the file is already complete; that byte is dropped.
+		from := start
+		if total < 0 && from > 0 {
+			from--
+		}
+		end := from + chunkSize - 1
+		if total >= 0 && end >= total {
+			end = total - 1
+		}
+		header := http.Header{}
+		header.Set("Content-Range", fmt.Sprintf("%d-%d/%d", from, end, max(total, 0)))
+		res, err := b.doAuthenticated(ctx, options, urlString, nil, header)
+		if err != nil {
+			return written, err
+		}
+
+		body := res.Body
+		rangeStart, rangeEnd, size, ok := parseContentRange(res.Header.Get("Content-Range"))
+		if !ok {
+			// The whole file was sent at once.
+			rangeStart, rangeEnd, size = 0, int64(len(body))-1, int64(len(body))
+		}
+		if skip := start - rangeStart; skip > 0 {
+			body = body[min(skip, int64(len(body))):]
+		}
+		n, err := w.Write(body)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+		if rangeEnd+1 <= start && rangeEnd+1 < size {
+			return written, fmt.Errorf("unexpected Content-Range %q for requested range %d-%d", res.Header.Get("Content-Range"), from, end)
+		}
+		start, total = rangeEnd+1, size
+		if opts.Progress != nil {
+			opts.Progress(min(start, total), total)
+		}
+	}
+	return written, nil
+}
+
+// parseContentRange parses a "start-end/size" Content-Range header, with or
+// without the "bytes " unit.
+func parseContentRange(v string) (start, end, size int64, ok bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "bytes ")
+	if _, err := fmt.Sscanf(v, "%d-%d/%d", &start, &end, &size); err != nil {
+		return 0, 0, 0, false
+	}
+	return start, end, size, true
+}
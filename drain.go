@@ -0,0 +1,121 @@
+package bigip
+
+import (
+	"context"
+	"time"
+)
+
+// DrainOptions tunes DrainPoolMember and DrainNode.
+type DrainOptions struct {
+	// MaxConnections is the number of server-side connections left at which
+	// draining is over. Defaults to 0.
+	MaxConnections int64
+	// Timeout bounds the wait for connections to drain. Once it has elapsed
+	// the object is forced offline whatever the connections left. Defaults
+	// to waiting until ctx is done.
+	Timeout time.Duration
+	// Progress, if set, is called with the number of server-side connections
+	// left every time it is checked.
+	Progress func(connections int64)
+}
+
+// DrainPoolMember disables member of pool so that it takes no new
+// connections, waits for its server-side connections to drain according to
+// opts, then forces it offline. It returns the connections left when it was
+// forced offline. If ctx is done first, the member is left disabled and the
+// context error is returned.
+func (b *BigIP) DrainPoolMember(ctx context.Context, pool, member string, opts *DrainOptions) (int64, error) {
+	client := b.WithContext(ctx)
+	return client.drain(ctx, opts, func(config *Node) error {
+		return client.put(config, uriLtm, uriPool, pool, uriPoolMember, member)
+	}, func() (*Stats, error) {
+		return client.getObjectStats(uriLtm, uriPool, pool, uriPoolMember, member, uriStats)
+	})
+}
+
+// DrainNode is DrainPoolMember for the node called name, and so for every
+// pool member on it.
+func (b *BigIP) DrainNode(ctx context.Context, name string, opts *DrainOptions) (int64, error) {
+	client := b.WithContext(ctx)
+	return client.drain(ctx, opts, func(config *Node) error {
+		return client.put(config, uriLtm, uriNode, name)
+	}, func() (*Stats, error) {
+		return client.getObjectStats(uriLtm, uriNode, name, uriStats)
+	})
+}
+
+func (b *BigIP) drain(ctx context.Context, opts *DrainOptions, setStatus func(config *Node) error, stats func() (*Stats, error)) (int64, error) {
+	o := DrainOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if err := setStatus(&Node{Session: "user-disabled"}); err != nil {
+		return 0, err
+	}
+
+	waitCtx := ctx
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+	var conns int64
+	err := poll(waitCtx, statusPollInterval, func(context.Context) (bool, error) {
+		s, err := stats()
+		if err != nil {
+			return IsNotFound(err), err
+		}
+		conns = s.Values["serverside.curConns"]
+		if o.Progress != nil {
+			o.Progress(conns)
+		}
+		return conns <= o.MaxConnections, nil
+	})
+	if err != nil {
+		// Only the drain timeout lets the object be forced offline early.
+		if ctx.Err() != nil || waitCtx.Err() == nil {
+			return conns, err
+		}
+		b.log().Warn("drain timed out, forcing offline", "connections", conns)
+	}
+	return conns, setStatus(&Node{State: "user-down", Session: "user-disabled"})
+}
+
+// UndrainPoolMember enables member of pool, bringing it back up if it was
+// forced offline, and waits until it is available again. Members without a
+// monitor are taken as available as soon as they are enabled.
+func (b *BigIP) UndrainPoolMember(ctx context.Context, pool, member string) (*Availability, error) {
+	client := b.WithContext(ctx)
+	return client.undrain(ctx, func(config *Node) error {
+		return client.put(config, uriLtm, uriPool, pool, uriPoolMember, member)
+	}, func() (*Availability, error) {
+		return client.PoolMemberAvailability(pool, member)
+	})
+}
+
+// UndrainNode is UndrainPoolMember for the node called name.
+func (b *BigIP) UndrainNode(ctx context.Context, name string) (*Availability, error) {
+	client := b.WithContext(ctx)
+	return client.undrain(ctx, func(config *Node) error {
+		return client.put(config, uriLtm, uriNode, name)
+	}, func() (*Availability, error) {
+		return client.NodeAvailability(name)
+	})
+}
+
+func (b *BigIP) undrain(ctx context.Context, setStatus func(config *Node) error, availability func() (*Availability, error)) (*Availability, error) {
+	// Unlike NodeStatus "enable", undraining also lifts a forced offline.
+	if err := setStatus(&Node{State: "user-up", Session: "user-enabled"}); err != nil {
+		return nil, err
+	}
+	var a *Availability
+	err := poll(ctx, statusPollInterval, func(context.Context) (bool, error) {
+		var err error
+		a, err = availability()
+		if err != nil {
+			return IsNotFound(err), err
+		}
+		return a.EnabledState == "enabled" && (a.AvailabilityState == "available" || a.AvailabilityState == "unknown"), nil
+	})
+	return a, err
+}
@@ -0,0 +1,105 @@
+package bigip
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned by APICall, and everything built on it, whenever the
+// BIG-IP answers with an HTTP status of 400 or above. Use errors.As to
+// inspect it, or one of the IsNotFound/IsConflict/... helpers.
+type APIError struct {
+	// StatusCode is the HTTP status returned by the device.
+	StatusCode int
+	Method     string
+	URL        string
+	// Code, Message and ErrorStack are decoded from the iControl REST error
+	// body, when the device sent one.
+	Code       int
+	Message    string
+	ErrorStack []string
+	// Body holds the raw response body.
+	Body []byte
+}
+
+// Error returns the iControl error message, falling back to the HTTP status
+// and raw body when the device did not send a JSON error.
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return fmt.Sprintf("HTTP %d :: %s", e.StatusCode, string(e.Body))
+}
+
+// newAPIError builds an APIError from a failed response. The body is only
+// decoded as a RequestError when the device labelled it as JSON.
+func newAPIError(method, url string, res *CallResponse) *APIError {
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		Method:     strings.ToUpper(method),
+		URL:        url,
+		Body:       res.Body,
+	}
+	if strings.Contains(res.Header.Get("Content-Type"), "application/json") {
+		var reqError RequestError
+		if err := json.Unmarshal(res.Body, &reqError); err == nil {
+			apiErr.Code = reqError.Code
+			apiErr.Message = reqError.Message
+			apiErr.ErrorStack = reqError.ErrorStack
+		}
+	}
+	return apiErr
+}
+
+// hasStatus reports whether either the HTTP status or the iControl code
+// matches code. AS3 in particular nests the real status in the body.
+func (e *APIError) hasStatus(code int) bool {
+	return e.StatusCode == code || e.Code == code
+}
+
+func asAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// IsNotFound reports whether err means the requested object does not exist.
+func IsNotFound(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.hasStatus(http.StatusNotFound)
+}
+
+// IsConflict reports whether err means the request clashed with the current
+// state of the device, typically because the object already exists.
+func IsConflict(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.hasStatus(http.StatusConflict)
+}
+
+// IsAlreadyExists reports whether err means the object being created is
+// already present on the device.
+func IsAlreadyExists(err error) bool {
+	if IsConflict(err) {
+		return true
+	}
+	apiErr, ok := asAPIError(err)
+	return ok && strings.Contains(strings.ToLower(apiErr.Message), "already exists")
+}
+
+// IsUnauthorized reports whether err means the credentials or token were rejected.
+func IsUnauthorized(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.hasStatus(http.StatusUnauthorized)
+}
+
+// IsValidationError reports whether err means the device refused the request
+// body, for instance a configuration error or an invalid declaration.
+func IsValidationError(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && (apiErr.hasStatus(http.StatusBadRequest) || apiErr.hasStatus(http.StatusUnprocessableEntity))
+}
@@ -1,9 +1,9 @@
 package bigip
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"time"
 )
@@ -283,7 +283,7 @@ func (b *BigIP) UploadFastTemplate(tmplpath *os.File, tmplname string) error {
 	if err != nil {
 		return err
 	}
-	log.Printf("[DEBUG]Template Path:%+v", tmplpath.Name())
+	b.log().Debug("FAST template set uploaded", "template_set", tmplname, "path", tmplpath.Name())
 	payload := FastTemplateSet{
 		Name: tmplname,
 	}
@@ -352,6 +352,12 @@ func (b *BigIP) GetFastApp(tenant, app string) (string, error) {
 
 // PostFastAppBigip used for posting FAST json file to BIGIP
 func (b *BigIP) PostFastAppBigip(body, fastTemplate, userAgent string) (tenant, app string, err error) {
+	return b.PostFastAppBigipContext(b.context(), body, fastTemplate, userAgent)
+}
+
+// PostFastAppBigipContext is the context-aware variant of PostFastAppBigip. Task polling stops once ctx is done.
+func (b *BigIP) PostFastAppBigipContext(ctx context.Context, body, fastTemplate, userAgent string) (tenant, app string, err error) {
+	b = b.WithContext(ctx)
 	param := []byte(body)
 	jsonRef := make(map[string]interface{})
 	json.Unmarshal(param, &jsonRef)
@@ -359,7 +365,7 @@ func (b *BigIP) PostFastAppBigip(body, fastTemplate, userAgent string) (tenant,
 		Name:       fastTemplate,
 		Parameters: jsonRef,
 	}
-	log.Printf("[DEBUG]payload = %+v", payload)
+	b.log().Debug("creating FAST application", "template", fastTemplate, "payload", payload)
 	resp, err := b.postReq(payload, uriMgmt, uriShared, uriFast, uriFastApp, userAgent)
 	if err != nil {
 		return "", "", err
@@ -372,28 +378,36 @@ func (b *BigIP) PostFastAppBigip(body, fastTemplate, userAgent string) (tenant,
 		return "", "", err
 	}
 	respCode := taskStatus.Code
-	log.Printf("[DEBUG]Initial response code = %+v,ID = %+v", respCode, respID)
+	b.log().Debug("FAST task started", "task_id", respID, "status", respCode)
 	for respCode != 200 {
 		fastTask, err := b.getFastTaskStatus(respID)
 		if err != nil {
 			return "", "", err
 		}
 		respCode = fastTask.Code
-		log.Printf("[DEBUG]Response code = %+v,ID = %+v", respCode, respID)
+		b.log().Debug("FAST task polled", "task_id", respID, "status", respCode)
 		if respCode == 200 {
-			log.Printf("[DEBUG]Sucessfully Created Application with ID  = %v", respID)
+			b.log().Debug("FAST application created", "task_id", respID, "tenant", fastTask.Tenant, "application", fastTask.Application)
 			break // break here
 		}
 		if respCode >= 400 {
 			return "", "", fmt.Errorf("FAST Application creation failed with :%+v", fastTask.Message)
 		}
-		time.Sleep(3 * time.Second)
+		if err := sleepContext(ctx, 3*time.Second); err != nil {
+			return "", "", err
+		}
 	}
 	return taskStatus.Tenant, taskStatus.Application, err
 }
 
 // ModifyFastAppBigip used for updating FAST application on BIGIP
 func (b *BigIP) ModifyFastAppBigip(body, fastTenant, fastApp string) error {
+	return b.ModifyFastAppBigipContext(b.context(), body, fastTenant, fastApp)
+}
+
+// ModifyFastAppBigipContext is the context-aware variant of ModifyFastAppBigip. Task polling stops once ctx is done.
+func (b *BigIP) ModifyFastAppBigipContext(ctx context.Context, body, fastTenant, fastApp string) error {
+	b = b.WithContext(ctx)
 	param := []byte(body)
 	jsonRef := make(map[string]interface{})
 	json.Unmarshal(param, &jsonRef)
@@ -412,7 +426,7 @@ func (b *BigIP) ModifyFastAppBigip(body, fastTenant, fastApp string) error {
 		return err
 	}
 	respCode := taskStatus.Code
-	log.Printf("[DEBUG]Code = %+v,ID = %+v", respCode, respID)
+	b.log().Debug("FAST task started", "task_id", respID, "status", respCode, "tenant", fastTenant, "application", fastApp)
 	for respCode != 200 {
 		fastTask, err := b.getFastTaskStatus(respID)
 		if err != nil {
@@ -420,20 +434,28 @@ func (b *BigIP) ModifyFastAppBigip(body, fastTenant, fastApp string) error {
 		}
 		respCode = fastTask.Code
 		if respCode == 200 {
-			log.Printf("[DEBUG]Sucessfully Modified Application with ID  = %v", respID)
+			b.log().Debug("FAST application modified", "task_id", respID, "tenant", fastTenant, "application", fastApp)
 			break // break here
 		}
 		if respCode >= 400 {
 			return fmt.Errorf("FAST Application update failed with :%+v", fastTask.Message)
 			//return fmt.Errorf("FAST Application update failed")
 		}
-		time.Sleep(3 * time.Second)
+		if err := sleepContext(ctx, 3*time.Second); err != nil {
+			return err
+		}
 	}
 	return err
 }
 
 // DeleteFastAppBigip used for deleting FAST application on BIGIP
 func (b *BigIP) DeleteFastAppBigip(fastTenant, fastApp string) error {
+	return b.DeleteFastAppBigipContext(b.context(), fastTenant, fastApp)
+}
+
+// DeleteFastAppBigipContext is the context-aware variant of DeleteFastAppBigip. Task polling stops once ctx is done.
+func (b *BigIP) DeleteFastAppBigipContext(ctx context.Context, fastTenant, fastApp string) error {
+	b = b.WithContext(ctx)
 	resp, err := b.deleteReq(uriMgmt, uriShared, uriFast, uriFastApp, fastTenant, fastApp)
 	if err != nil {
 		return err
@@ -446,7 +468,7 @@ func (b *BigIP) DeleteFastAppBigip(fastTenant, fastApp string) error {
 		return err
 	}
 	respCode := taskStatus.Code
-	log.Printf("[DEBUG]Code = %+v,ID = %+v", respCode, respID)
+	b.log().Debug("FAST task started", "task_id", respID, "status", respCode, "tenant", fastTenant, "application", fastApp)
 	for respCode != 200 {
 		fastTask, err := b.getFastTaskStatus(respID)
 		if err != nil {
@@ -454,13 +476,15 @@ func (b *BigIP) DeleteFastAppBigip(fastTenant, fastApp string) error {
 		}
 		respCode = fastTask.Code
 		if respCode == 200 {
-			log.Printf("[DEBUG]Sucessfully Deleted Application with ID  = %v", respID)
+			b.log().Debug("FAST application deleted", "task_id", respID, "tenant", fastTenant, "application", fastApp)
 			break // break here
 		}
 		if respCode >= 400 {
 			return fmt.Errorf("FAST Application deletion failed")
 		}
-		time.Sleep(3 * time.Second)
+		if err := sleepContext(ctx, 3*time.Second); err != nil {
+			return err
+		}
 	}
 	return nil
 }
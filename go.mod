@@ -1,8 +1,13 @@
 module github.com/f5devcentral/go-bigip
 
-go 1.20
+go 1.21
 
-require github.com/stretchr/testify v1.2.1
+require (
+	github.com/dlclark/regexp2 v1.11.5
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.2
+	github.com/stretchr/testify v1.2.1
+	golang.org/x/text v0.14.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
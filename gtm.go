@@ -12,7 +12,6 @@ package bigip
 
 import (
 	"encoding/json"
-	"log"
 )
 
 const (
@@ -504,7 +503,7 @@ func (b *BigIP) DeleteGtmmonitor(name string) error {
 }
 
 func (b *BigIP) CreateGtmserver(p *Server) error {
-	log.Println(" what is the complete payload    ", p)
+	b.log().Debug("creating GTM server", "server", p.Name)
 	return b.post(p, uriGtm, uriServer)
 }
 
@@ -534,10 +533,10 @@ func (b *BigIP) GetGtmserver(name string) (*Server, error) {
 	}
 	err, ok = b.getForEntity(&vsResponse, uriGtm, uriServer, name, "virtual-servers")
 	if err != nil {
-		log.Printf("[DEBUG] Error fetching GTM server virtual servers for %s: %v", name, err)
+		b.log().Debug("failed to fetch GTM server virtual servers", "server", name, "error", err)
 	} else if ok && len(vsResponse.Items) > 0 {
 		p.GTMVirtual_Server = vsResponse.Items
-		log.Printf("[DEBUG] Fetched %d virtual servers for GTM server %s", len(vsResponse.Items), name)
+		b.log().Debug("fetched GTM server virtual servers", "server", name, "count", len(vsResponse.Items))
 	}
 
 	return &p, nil
@@ -636,21 +635,19 @@ func (b *BigIP) GetGTMPool(fullPath string, poolType string) (*GtmPool, error) {
 	var membersResponse struct {
 		Items []GtmPoolMembers `json:"items"`
 	}
-	log.Printf("[DEBUG] Attempting to fetch members for GTM pool: %s, type: %s, path: gtm/pool/%s/%s/members", fullPath, poolType, poolType, fullPath)
 	err, ok = b.getForEntity(&membersResponse, uriGtm, "pool", poolType, fullPath, "members")
 	if err != nil {
-		log.Printf("[DEBUG] Error fetching GTM pool members for %s: %v", fullPath, err)
+		b.log().Debug("failed to fetch GTM pool members", "pool", fullPath, "type", poolType, "error", err)
 		// Don't fail - pool might not have members or endpoint might not be accessible
 	} else if ok {
-		log.Printf("[DEBUG] Members response OK, found %d items", len(membersResponse.Items))
 		if len(membersResponse.Items) > 0 {
 			pool.Members = membersResponse.Items
-			log.Printf("[DEBUG] Fetched %d members for GTM pool %s", len(membersResponse.Items), fullPath)
+			b.log().Debug("fetched GTM pool members", "pool", fullPath, "type", poolType, "count", len(membersResponse.Items))
 		} else {
-			log.Printf("[DEBUG] No members found for GTM pool %s", fullPath)
+			b.log().Debug("no members found for GTM pool", "pool", fullPath, "type", poolType)
 		}
 	} else {
-		log.Printf("[DEBUG] Members fetch returned ok=false for pool %s", fullPath)
+		b.log().Debug("no members found for GTM pool", "pool", fullPath, "type", poolType)
 	}
 
 	return &pool, nil
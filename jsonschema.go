@@ -0,0 +1,398 @@
+package bigip
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dlclark/regexp2"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// SchemaProblem is a place where a document does not match its JSON schema.
+type SchemaProblem struct {
+	// Path is the JSON pointer to the offending value, such as
+	// "/declaration/Example/web/vs".
+	Path    string
+	Message string
+}
+
+func (p SchemaProblem) String() string {
+	path := p.Path
+	if path == "" {
+		path = "/"
+	}
+	return path + ": " + p.Message
+}
+
+// jsonSchema checks documents against a JSON schema, draft-07 unless the
+// schema says otherwise, without network access: references are resolved
+// within the documents it is built from. Patterns are ECMAScript regular
+// expressions, as JSON schemas define them. Formats the validator does not
+// know, such as the "f5ip" format of the AS3 schemas, and patterns it cannot
+// compile, are not checked; they are listed in unchecked.
+type jsonSchema struct {
+	schema *jsonschema.Schema
+	// unchecked describes the patterns and formats of the documents that
+	// are not checked, sorted.
+	unchecked []string
+}
+
+// schemaPrinter renders the messages of the problems.
+var schemaPrinter = message.NewPrinter(language.English)
+
+// newJSONSchema builds a schema from documents; the first one is the root.
+// The others are found by their $id.
+func newJSONSchema(documents ...[]byte) (*jsonSchema, error) {
+	s := &jsonSchema{}
+	engine := &ecmaScriptEngine{unsupported: map[string]error{}}
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(jsonschema.Draft7)
+	c.UseRegexpEngine(engine.compile)
+	c.UseLoader(noSchemaLoader{})
+	formats := map[string]bool{}
+	root := ""
+	for i, data := range documents {
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON schema: %w", err)
+		}
+		location := "urn:go-bigip:schema:" + strconv.Itoa(i)
+		if obj, ok := doc.(map[string]interface{}); ok {
+			if id, ok := obj["$id"].(string); ok && id != "" {
+				location = strings.TrimSuffix(id, "#")
+			}
+		}
+		if err := c.AddResource(location, doc); err != nil {
+			return nil, fmt.Errorf("invalid JSON schema: %w", err)
+		}
+		if i == 0 {
+			root = location
+		}
+		findFormats(doc, formats)
+	}
+	schema, err := c.Compile(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	s.schema = schema
+	engine.compiled()
+	for _, pattern := range sortedKeys(engine.unsupported) {
+		s.unchecked = append(s.unchecked, fmt.Sprintf("pattern %q: %v", pattern, engine.unsupported[pattern]))
+	}
+	for _, format := range sortedKeys(formats) {
+		if !knownFormat(format) {
+			s.unchecked = append(s.unchecked, fmt.Sprintf("format %q", format))
+		}
+	}
+	sort.Strings(s.unchecked)
+	return s, nil
+}
+
+// validate returns the problems of value, a decoded JSON document.
+func (s *jsonSchema) validate(value interface{}) []SchemaProblem {
+	err := s.schema.Validate(value)
+	if err == nil {
+		return nil
+	}
+	invalid, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return []SchemaProblem{{"", err.Error()}}
+	}
+	return schemaProblems(invalid, nil, nil)
+}
+
+// schemaProblems flattens the tree of errors rooted at err into problems,
+// one per failed keyword. The schemas tried by a failed anyOf or oneOf are
+// not told apart, nor are the errors of an invalid property name: the
+// keyword itself is the problem. parent is the location of the value err
+// is found in, which an invalid property name is reported at, since its
+// errors are about the name.
+func schemaProblems(err *jsonschema.ValidationError, parent []string, problems []SchemaProblem) []SchemaProblem {
+	location := err.InstanceLocation
+	switch err.ErrorKind.(type) {
+	case *kind.PropertyNames:
+		location = parent
+	case *kind.AnyOf, *kind.OneOf:
+	default:
+		if len(err.Causes) > 0 {
+			for _, cause := range err.Causes {
+				problems = schemaProblems(cause, location, problems)
+			}
+			return problems
+		}
+	}
+	var path strings.Builder
+	for _, token := range location {
+		path.WriteString("/" + escapePointer(token))
+	}
+	return append(problems, SchemaProblem{path.String(), err.ErrorKind.LocalizedString(schemaPrinter)})
+}
+
+// findFormats adds to formats the formats used by schema and the schemas
+// nested in it.
+func findFormats(schema interface{}, formats map[string]bool) {
+	switch sch := schema.(type) {
+	case map[string]interface{}:
+		for key, value := range sch {
+			switch key {
+			case "enum", "const", "default", "examples":
+				// Values, not schemas.
+				continue
+			case "format":
+				if format, ok := value.(string); ok {
+					formats[format] = true
+					continue
+				}
+			}
+			findFormats(value, formats)
+		}
+	case []interface{}:
+		for _, item := range sch {
+			findFormats(item, formats)
+		}
+	}
+}
+
+var (
+	knownFormatsMu sync.Mutex
+	knownFormats   = map[string]bool{}
+)
+
+// knownFormat reports whether the validator checks the values of format.
+func knownFormat(format string) bool {
+	knownFormatsMu.Lock()
+	defer knownFormatsMu.Unlock()
+	known, ok := knownFormats[format]
+	if !ok {
+		c := jsonschema.NewCompiler()
+		c.DefaultDraft(jsonschema.Draft7)
+		if err := c.AddResource("urn:go-bigip:format", map[string]interface{}{"format": format}); err == nil {
+			if schema, err := c.Compile("urn:go-bigip:format"); err == nil {
+				known = schema.Format != nil
+			}
+		}
+		knownFormats[format] = known
+	}
+	return known
+}
+
+// ecmaScriptEngine compiles the patterns of a schema as ECMAScript regular
+// expressions. A pattern of the schema it cannot compile matches anything,
+// and is kept in unsupported with the reason. Once the schema is compiled,
+// it only checks the values of the "regex" format.
+type ecmaScriptEngine struct {
+	mu          sync.Mutex
+	unsupported map[string]error
+	done        bool
+}
+
+func (e *ecmaScriptEngine) compile(pattern string) (jsonschema.Regexp, error) {
+	re, err := regexp2.Compile(pattern, regexp2.ECMAScript)
+	if err != nil {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.done {
+			return nil, err
+		}
+		e.unsupported[pattern] = err
+		return anyString(pattern), nil
+	}
+	return (*ecmaScriptRegexp)(re), nil
+}
+
+func (e *ecmaScriptEngine) compiled() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.done = true
+}
+
+type ecmaScriptRegexp regexp2.Regexp
+
+func (re *ecmaScriptRegexp) MatchString(s string) bool {
+	matched, err := (*regexp2.Regexp)(re).MatchString(s)
+	return err == nil && matched
+}
+
+func (re *ecmaScriptRegexp) String() string {
+	return (*regexp2.Regexp)(re).String()
+}
+
+// anyString stands for a pattern that cannot be compiled.
+type anyString string
+
+func (anyString) MatchString(string) bool { return true }
+
+func (re anyString) String() string { return string(re) }
+
+// noSchemaLoader refuses to fetch the schemas referred to but not given,
+// so that validating never reaches the network or the disk.
+type noSchemaLoader struct{}
+
+func (noSchemaLoader) Load(url string) (any, error) {
+	return nil, fmt.Errorf("schema %s not provided", url)
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// sortProblems orders problems by path, for stable reports.
+func sortProblems(problems []SchemaProblem) {
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Path < problems[j].Path })
+}
+
+// SchemaError lists the problems of a declaration that does not match its
+// JSON schema.
+type SchemaError struct {
+	// Extension is the extension the declaration is for, such as "AS3".
+	Extension string
+	// SchemaVersion is the version of the schema the declaration was
+	// checked against, empty for the built-in schema.
+	SchemaVersion string
+	Problems      []SchemaProblem
+}
+
+func (e *SchemaError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		problems[i] = p.String()
+	}
+	return e.Extension + " declaration is invalid: " + strings.Join(problems, "; ")
+}
+
+// schemaVersionPattern matches the versions of the extensions and of their
+// schemas, such as "3.50.0".
+var schemaVersionPattern = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
+
+// schemaValidator checks the declarations of an extension, such as AS3 or
+// DO, against the JSON schema of the oldest release that supports their
+// schemaVersion, or against a built-in schema when none was added. Add the
+// schemas before validating from several goroutines.
+type schemaValidator struct {
+	extension string
+	// schemas holds the schemas of the releases by version.
+	schemas map[string]*jsonSchema
+	core    *jsonSchema
+}
+
+func newSchemaValidator(extension, core string) schemaValidator {
+	s, err := newJSONSchema([]byte(core))
+	if err != nil {
+		panic(err)
+	}
+	return schemaValidator{extension: extension, schemas: map[string]*jsonSchema{}, core: s}
+}
+
+// AddSchema adds schema, the JSON schema of the declarations of the release
+// version, such as "3.50.0", along with the schemas it refers to. The
+// patterns and formats of the schemas that cannot be checked are listed by
+// Unchecked.
+func (v *schemaValidator) AddSchema(version string, schema []byte, referenced ...[]byte) error {
+	if _, ok := parseSchemaVersion(version); !ok {
+		return fmt.Errorf("invalid %s version %q", v.extension, version)
+	}
+	s, err := newJSONSchema(append([][]byte{schema}, referenced...)...)
+	if err != nil {
+		return err
+	}
+	v.schemas[version] = s
+	return nil
+}
+
+// Unchecked describes the patterns and formats of the schema of version,
+// or of the built-in schema when version is empty, that declarations are
+// not checked against: patterns that are not valid ECMAScript regular
+// expressions, and formats the validator does not know, such as "f5ip". It
+// returns nil if there is no schema for version.
+func (v *schemaValidator) Unchecked(version string) []string {
+	schema := v.core
+	if version != "" {
+		schema = v.schemas[version]
+	}
+	if schema == nil {
+		return nil
+	}
+	return append([]string(nil), schema.unchecked...)
+}
+
+// Versions returns the versions of the schemas added, oldest first.
+func (v *schemaValidator) Versions() []string {
+	versions := sortedKeys(v.schemas)
+	sort.Slice(versions, func(i, j int) bool { return compareSchemaVersions(versions[i], versions[j]) < 0 })
+	return versions
+}
+
+// validate checks declaration, a decoded JSON document, whose schemaVersion
+// is declared. prefix is prepended to the paths of the problems, when
+// declaration is nested in the document the caller was given.
+func (v *schemaValidator) validate(declared string, declaration interface{}, prefix string) error {
+	schema, version := v.core, ""
+	if len(v.schemas) > 0 {
+		if _, ok := parseSchemaVersion(declared); !ok {
+			return fmt.Errorf("missing or invalid %s schemaVersion %q", v.extension, declared)
+		}
+		for _, candidate := range v.Versions() {
+			if compareSchemaVersions(candidate, declared) >= 0 {
+				schema, version = v.schemas[candidate], candidate
+				break
+			}
+		}
+		if version == "" {
+			return fmt.Errorf("no %s schema supports schemaVersion %q, have %s", v.extension, declared, strings.Join(v.Versions(), ", "))
+		}
+	}
+	problems := schema.validate(declaration)
+	if len(problems) == 0 {
+		return nil
+	}
+	for i := range problems {
+		problems[i].Path = prefix + problems[i].Path
+	}
+	sortProblems(problems)
+	return &SchemaError{Extension: v.extension, SchemaVersion: version, Problems: problems}
+}
+
+func parseSchemaVersion(version string) ([3]int, bool) {
+	var parsed [3]int
+	m := schemaVersionPattern.FindStringSubmatch(version)
+	if m == nil || m[0] != version {
+		return parsed, false
+	}
+	for i := range parsed {
+		parsed[i], _ = strconv.Atoi(m[i+1])
+	}
+	return parsed, true
+}
+
+// compareSchemaVersions compares the versions a and b like strings.Compare.
+// Invalid versions sort first.
+func compareSchemaVersions(a, b string) int {
+	va, okA := parseSchemaVersion(a)
+	vb, okB := parseSchemaVersion(b)
+	if !okA || !okB {
+		switch {
+		case okA:
+			return 1
+		case okB:
+			return -1
+		}
+		return 0
+	}
+	for i := range va {
+		if va[i] < vb[i] {
+			return -1
+		}
+		if va[i] > vb[i] {
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,177 @@
+package bigip
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ListOptions narrows down a collection listing using the iControl REST
+// query parameters. The zero value lists the whole collection in one request.
+type ListOptions struct {
+	// Top is the page size ($top). When set, the device returns a nextLink
+	// to the following page, which ListAll and Iterate follow.
+	Top int
+	// Skip is the number of objects to skip ($skip).
+	Skip int
+	// Filter restricts the objects returned ($filter), for instance
+	// "partition eq Prod".
+	Filter string
+	// Select lists the attributes to return ($select), for instance
+	// []string{"name", "fullPath"}.
+	Select []string
+	// ExpandSubcollections inlines subcollections such as pool members.
+	ExpandSubcollections bool
+}
+
+// query encodes the options as a query string, without the leading "?".
+func (o *ListOptions) query() string {
+	if o == nil {
+		return ""
+	}
+	var params []string
+	add := func(key, value string) {
+		// iControl REST wants %20 rather than + between the words of a filter.
+		params = append(params, key+"="+strings.ReplaceAll(url.QueryEscape(value), "+", "%20"))
+	}
+	if o.Top > 0 {
+		add("$top", strconv.Itoa(o.Top))
+	}
+	if o.Skip > 0 {
+		add("$skip", strconv.Itoa(o.Skip))
+	}
+	if o.Filter != "" {
+		add("$filter", o.Filter)
+	}
+	if len(o.Select) > 0 {
+		add("$select", strings.Join(o.Select, ","))
+	}
+	if o.ExpandSubcollections {
+		add("expandSubcollections", "true")
+	}
+	return strings.Join(params, "&")
+}
+
+// Page is a single page of a collection listing.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// NextLink points to the following page, when there is one.
+	NextLink   string `json:"nextLink,omitempty"`
+	TotalItems int    `json:"totalItems,omitempty"`
+}
+
+// ListPage returns the first page of the collection selected by opts.
+func (r *Resource[T]) ListPage(opts *ListOptions) (*Page[T], error) {
+	u := r.b.iControlPath(r.path)
+	if q := opts.query(); q != "" {
+		u += "?" + q
+	}
+	return r.getPage(u)
+}
+
+// ListAll returns every object selected by opts, following nextLink from
+// page to page. Use Iterate to avoid holding a large collection in memory.
+func (r *Resource[T]) ListAll(opts *ListOptions) ([]T, error) {
+	var items []T
+	it := r.Iterate(opts)
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+	return items, it.Err()
+}
+
+// Iterate returns an Iterator over the objects selected by opts. Pages are
+// fetched lazily, following nextLink; set opts.Top to choose the page size.
+func (r *Resource[T]) Iterate(opts *ListOptions) *Iterator[T] {
+	return &Iterator[T]{r: r, opts: opts}
+}
+
+// getPage fetches a page from u, a path relative to the device, such as
+// "ltm/pool?$top=10" or "mgmt/tm/ltm/pool?$skip=10&$top=10".
+func (r *Resource[T]) getPage(u string) (*Page[T], error) {
+	resp, err := r.b.APICall(&APIRequest{
+		Method:      "get",
+		URL:         u,
+		ContentType: "application/json",
+	})
+	if err != nil {
+		return nil, err
+	}
+	var page Page[T]
+	if err := json.Unmarshal(resp, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Iterator walks a collection one object at a time, in the style of
+// bufio.Scanner:
+//
+//	it := bigip.NewResource[bigip.PoolMember](b, "ltm", "pool", "/Common/web", "members").Iterate(&bigip.ListOptions{Top: 500})
+//	for it.Next() {
+//		member := it.Item()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator[T any] struct {
+	r       *Resource[T]
+	opts    *ListOptions
+	started bool
+	next    string
+	items   []T
+	item    T
+	err     error
+}
+
+// Next advances to the next object, fetching the next page when needed. It
+// returns false when the collection is exhausted or a request failed.
+func (it *Iterator[T]) Next() bool {
+	for len(it.items) == 0 {
+		if it.err != nil || (it.started && it.next == "") {
+			return false
+		}
+		var page *Page[T]
+		if !it.started {
+			it.started = true
+			page, it.err = it.r.ListPage(it.opts)
+		} else {
+			page, it.err = it.r.getPage(it.next)
+		}
+		if it.err != nil {
+			return false
+		}
+		it.items, it.next = page.Items, ""
+		if page.NextLink != "" {
+			it.next, it.err = nextLinkPath(page.NextLink)
+		}
+	}
+	it.item, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Item returns the current object.
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// nextLinkPath strips the scheme and host from a nextLink, which the device
+// always reports as https://localhost/..., so it can be requested from Host.
+func nextLinkPath(link string) (string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+	p := strings.TrimPrefix(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		p += "?" + u.RawQuery
+	}
+	return p, nil
+}
@@ -0,0 +1,26 @@
+package bigip
+
+import (
+	"context"
+	"log/slog"
+)
+
+// discardHandler drops every record. It is the default so that the library
+// stays silent unless Config.Logger is set.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+var discardLogger = slog.New(discardHandler{})
+
+// log returns the logger configured for the client, or one that discards
+// everything.
+func (b *BigIP) log() *slog.Logger {
+	if b == nil || b.logger == nil {
+		return discardLogger
+	}
+	return b.logger
+}
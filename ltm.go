@@ -13,7 +13,6 @@ package bigip
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/url"
 	"strings"
 )
@@ -2486,6 +2485,17 @@ func (b *BigIP) Nodes() (*Nodes, error) {
 	return &nodes, nil
 }
 
+// NodesWithOptions returns the nodes selected by opts, following every page
+// of the listing.
+func (b *BigIP) NodesWithOptions(opts *ListOptions) (*Nodes, error) {
+	items, err := NewResource[Node](b, uriLtm, uriNode).ListAll(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Nodes{Nodes: items}, nil
+}
+
 // AddNode adds a new node to the BIG-IP system using the Node Spec
 func (b *BigIP) AddNode(config *Node) error {
 	return b.post(config, uriLtm, uriNode)
@@ -2554,8 +2564,11 @@ func (b *BigIP) ModifyNode(name string, config *Node) error {
 	return b.put(config, uriLtm, uriNode, name)
 }
 
-// NodeStatus changes the status of a node. <state> can be either
-// "enable" or "disable".
+// NodeStatus changes the status of a node. <state> can be "enable",
+// "disable", which only lets active and persistent connections through, or
+// "offline", which forces the node offline and only lets active connections
+// finish. "enable" does not bring a node forced offline back up; use
+// UndrainNode for that.
 func (b *BigIP) NodeStatus(name, state string) error {
 	config := &Node{}
 
@@ -2566,9 +2579,9 @@ func (b *BigIP) NodeStatus(name, state string) error {
 	case "disable":
 		// config.State = "unchecked"
 		config.Session = "user-disabled"
-		// case "offline":
-		// 	config.State = "user-down"
-		// 	config.Session = "user-disabled"
+	case "offline":
+		config.State = "user-down"
+		config.Session = "user-disabled"
 	}
 
 	return b.put(config, uriLtm, uriNode, name)
@@ -2659,6 +2672,17 @@ func (b *BigIP) Pools() (*Pools, error) {
 	return &pools, nil
 }
 
+// PoolsWithOptions returns the pools selected by opts, following every page
+// of the listing.
+func (b *BigIP) PoolsWithOptions(opts *ListOptions) (*Pools, error) {
+	items, err := NewResource[Pool](b, uriLtm, uriPool).ListAll(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Pools{Pools: items}, nil
+}
+
 // PoolMembers returns a list of pool members for the given pool.
 func (b *BigIP) PoolMembers(name string) (*PoolMembers, error) {
 	var poolMembers PoolMembers
@@ -2763,9 +2787,9 @@ func (b *BigIP) DeletePoolMember(pool string, member string) error {
 	}
 }
 
-// PoolMemberStatus changes the status of a pool member. <state> can be either
-// "enable" or "disable". <member> must be in the form of <node>:<port>,
-// i.e.: "web-server1:443".
+// PoolMemberStatus changes the status of a pool member. <state> can be
+// "enable", "disable" or "offline", as for NodeStatus. <member> must be in
+// the form of <node>:<port>, i.e.: "web-server1:443".
 func (b *BigIP) PoolMemberStatus(pool string, member string, state string) error {
 	config := &Node{}
 
@@ -2776,9 +2800,9 @@ func (b *BigIP) PoolMemberStatus(pool string, member string, state string) error
 	case "disable":
 		// config.State = "unchecked"
 		config.Session = "user-disabled"
-		// case "offline":
-		// 	config.State = "user-down"
-		// 	config.Session = "user-disabled"
+	case "offline":
+		config.State = "user-down"
+		config.Session = "user-disabled"
 	}
 
 	return b.put(config, uriLtm, uriPool, pool, uriPoolMember, member)
@@ -2834,6 +2858,17 @@ func (b *BigIP) VirtualServers() (*VirtualServers, error) {
 	return &vs, nil
 }
 
+// VirtualServersWithOptions returns the virtual servers selected by opts,
+// following every page of the listing.
+func (b *BigIP) VirtualServersWithOptions(opts *ListOptions) (*VirtualServers, error) {
+	items, err := NewResource[VirtualServer](b, uriLtm, uriVirtual).ListAll(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VirtualServers{VirtualServers: items}, nil
+}
+
 // CreateVirtualServer adds a new virtual server to the BIG-IP system. <mask> can either be
 // in CIDR notation or decimal, i.e.: "24" or "255.255.255.0". A CIDR mask of "0" is the same
 // as "0.0.0.0".
@@ -2983,18 +3018,21 @@ func (b *BigIP) DeleteVirtualAddress(vaddr string) error {
 
 // Monitors returns a list of all HTTP, HTTPS, Gateway ICMP, ICMP, and TCP monitors.
 func (b *BigIP) Monitors() ([]Monitor, error) {
+	return b.MonitorsWithOptions(nil)
+}
+
+// MonitorsWithOptions returns the monitors of every type listed by Monitors
+// that are selected by opts. The options apply to each monitor type in turn.
+func (b *BigIP) MonitorsWithOptions(opts *ListOptions) ([]Monitor, error) {
 	var monitors []Monitor
 	monitorUris := []string{"http", "https", "icmp", "gateway-icmp", "tcp", "tcp-half-open", "ftp", "udp", "postgresql", "mysql", "mssql", "ldap", "smtp"}
 
 	for _, name := range monitorUris {
-		var m Monitors
-		err, _ := b.getForEntity(&m, uriLtm, uriMonitor, name)
+		m, err := NewResource[Monitor](b, uriLtm, uriMonitor, name).ListAll(opts)
 		if err != nil {
 			return nil, err
 		}
-		for _, monitor := range m.Monitors {
-			monitors = append(monitors, monitor)
-		}
+		monitors = append(monitors, m...)
 	}
 
 	return monitors, nil
@@ -3706,7 +3744,7 @@ func (b *BigIP) CreateSnatpool(name, partition string, members []string) error {
 		Partition: partition,
 		Members:   members,
 	}
-	log.Println("[INFO] Creating snatpool ", snatpool)
+	b.log().Debug("creating snatpool", "snatpool", name, "partition", partition)
 	return b.post(snatpool, uriLtm, uriSnatpool)
 }
 
@@ -3734,28 +3772,17 @@ func (b *BigIP) DeleteSnatpool(name string) error {
 
 // CookiePersistenceProfiles returns a list of cookie persist profiles
 func (b *BigIP) CookiePersistenceProfiles() (*CookiePersistenceProfiles, error) {
-	var cookiePersistenceProfiles CookiePersistenceProfiles
-	err, _ := b.getForEntity(&cookiePersistenceProfiles, uriLtm, uriPersistence, uriCookie)
+	items, err := NewResource[CookiePersistenceProfile](b, uriLtm, uriPersistence, uriCookie).List()
 	if err != nil {
 		return nil, err
 	}
 
-	return &cookiePersistenceProfiles, nil
+	return &CookiePersistenceProfiles{CookiePersistenceProfiles: items}, nil
 }
 
 // GetCookiePersistenceProfile gets a single cookie persist profile by name
 func (b *BigIP) GetCookiePersistenceProfile(name string) (*CookiePersistenceProfile, error) {
-	var cookiePersistenceProfile CookiePersistenceProfile
-	err, ok := b.getForEntity(&cookiePersistenceProfile, uriLtm, uriPersistence, uriCookie, name)
-	if err != nil {
-		return nil, err
-	}
-
-	if !ok {
-		return nil, nil
-	}
-
-	return &cookiePersistenceProfile, nil
+	return NewResource[CookiePersistenceProfile](b, uriLtm, uriPersistence, uriCookie).Get(name)
 }
 
 // CreateCookiePersistenceProfile creates a new cookie persist profile on the BIG-IP system.
@@ -3765,49 +3792,38 @@ func (b *BigIP) CreateCookiePersistenceProfile(config *PersistenceProfile) error
 		DefaultsFrom: parent,
 	}*/
 
-	return b.post(config, uriLtm, uriPersistence, uriCookie)
+	return NewResource[PersistenceProfile](b, uriLtm, uriPersistence, uriCookie).Create(config)
 }
 
 // AddCookiePersistenceProfile adds a cookie persist profile to the BIG-IP system
 func (b *BigIP) AddCookiePersistenceProfile(config *CookiePersistenceProfile) error {
-	return b.post(config, uriLtm, uriPersistence, uriCookie)
+	return NewResource[CookiePersistenceProfile](b, uriLtm, uriPersistence, uriCookie).Create(config)
 }
 
 // DeleteCookiePersistenceProfile removes a cookie persist profile.
 func (b *BigIP) DeleteCookiePersistenceProfile(name string) error {
-	return b.delete(uriLtm, uriPersistence, uriCookie, name)
+	return NewResource[CookiePersistenceProfile](b, uriLtm, uriPersistence, uriCookie).Delete(name)
 }
 
 // ModifyCookiePersistenceProfile allows you to change any attribute of a cookie persist profile.
 // Fields that can be modified are referenced in the CookiePersistenceProfile struct.
 func (b *BigIP) ModifyCookiePersistenceProfile(name string, config *CookiePersistenceProfile) error {
-	return b.patch(config, uriLtm, uriPersistence, uriCookie, name)
+	return NewResource[CookiePersistenceProfile](b, uriLtm, uriPersistence, uriCookie).Patch(name, config)
 }
 
 // DestAddrPersistenceProfiles returns a list of dest-addr persist profiles
 func (b *BigIP) DestAddrPersistenceProfiles() (*DestAddrPersistenceProfiles, error) {
-	var destAddrPersistenceProfiles DestAddrPersistenceProfiles
-	err, _ := b.getForEntity(&destAddrPersistenceProfiles, uriLtm, uriPersistence, uriDestAddr)
+	items, err := NewResource[DestAddrPersistenceProfile](b, uriLtm, uriPersistence, uriDestAddr).List()
 	if err != nil {
 		return nil, err
 	}
 
-	return &destAddrPersistenceProfiles, nil
+	return &DestAddrPersistenceProfiles{DestAddrPersistenceProfiles: items}, nil
 }
 
 // GetDestAddrPersistenceProfile gets a single dest-addr persist profile by name
 func (b *BigIP) GetDestAddrPersistenceProfile(name string) (*DestAddrPersistenceProfile, error) {
-	var destAddrPersistenceProfile DestAddrPersistenceProfile
-	err, ok := b.getForEntity(&destAddrPersistenceProfile, uriLtm, uriPersistence, uriDestAddr, name)
-	if err != nil {
-		return nil, err
-	}
-
-	if !ok {
-		return nil, nil
-	}
-
-	return &destAddrPersistenceProfile, nil
+	return NewResource[DestAddrPersistenceProfile](b, uriLtm, uriPersistence, uriDestAddr).Get(name)
 }
 
 // CreateDestAddrPersistenceProfile creates a new dest-addr persist profile on the BIG-IP system.
@@ -3817,49 +3833,38 @@ func (b *BigIP) CreateDestAddrPersistenceProfile(config *PersistenceProfile) err
 		DefaultsFrom: parent,
 	}*/
 
-	return b.post(config, uriLtm, uriPersistence, uriDestAddr)
+	return NewResource[PersistenceProfile](b, uriLtm, uriPersistence, uriDestAddr).Create(config)
 }
 
 // AddDestAddrPersistenceProfile adds a dest-addr persist profile to the BIG-IP system
 func (b *BigIP) AddDestAddrPersistenceProfile(config *DestAddrPersistenceProfile) error {
-	return b.post(config, uriLtm, uriPersistence, uriDestAddr)
+	return NewResource[DestAddrPersistenceProfile](b, uriLtm, uriPersistence, uriDestAddr).Create(config)
 }
 
 // DeleteDestAddrPersistenceProfile removes a dest-addr persist profile.
 func (b *BigIP) DeleteDestAddrPersistenceProfile(name string) error {
-	return b.delete(uriLtm, uriPersistence, uriDestAddr, name)
+	return NewResource[DestAddrPersistenceProfile](b, uriLtm, uriPersistence, uriDestAddr).Delete(name)
 }
 
 // ModifyDestAddrPersistenceProfile allows you to change any attribute of a dest-addr persist profile.
 // Fields that can be modified are referenced in the DestAddrPersistenceProfile struct.
 func (b *BigIP) ModifyDestAddrPersistenceProfile(name string, config *DestAddrPersistenceProfile) error {
-	return b.patch(config, uriLtm, uriPersistence, uriDestAddr, name)
+	return NewResource[DestAddrPersistenceProfile](b, uriLtm, uriPersistence, uriDestAddr).Patch(name, config)
 }
 
 // HashPersistenceProfiles returns a list of hash persist profiles
 func (b *BigIP) HashPersistenceProfiles() (*HashPersistenceProfiles, error) {
-	var hashPersistenceProfiles HashPersistenceProfiles
-	err, _ := b.getForEntity(&hashPersistenceProfiles, uriLtm, uriPersistence, uriHash)
+	items, err := NewResource[HashPersistenceProfile](b, uriLtm, uriPersistence, uriHash).List()
 	if err != nil {
 		return nil, err
 	}
 
-	return &hashPersistenceProfiles, nil
+	return &HashPersistenceProfiles{HashPersistenceProfiles: items}, nil
 }
 
 // GetHashPersistenceProfile gets a single hash persist profile by name
 func (b *BigIP) GetHashPersistenceProfile(name string) (*HashPersistenceProfile, error) {
-	var hashPersistenceProfile HashPersistenceProfile
-	err, ok := b.getForEntity(&hashPersistenceProfile, uriLtm, uriPersistence, uriHash, name)
-	if err != nil {
-		return nil, err
-	}
-
-	if !ok {
-		return nil, nil
-	}
-
-	return &hashPersistenceProfile, nil
+	return NewResource[HashPersistenceProfile](b, uriLtm, uriPersistence, uriHash).Get(name)
 }
 
 // CreateHashPersistenceProfile creates a new hash persist profile on the BIG-IP system.
@@ -3869,49 +3874,38 @@ func (b *BigIP) CreateHashPersistenceProfile(name string, parent string) error {
 		DefaultsFrom: parent,
 	}
 
-	return b.post(config, uriLtm, uriPersistence, uriHash)
+	return NewResource[PersistenceProfile](b, uriLtm, uriPersistence, uriHash).Create(config)
 }
 
 // AddHashPersistenceProfile adds a hash persist profile to the BIG-IP system
 func (b *BigIP) AddHashPersistenceProfile(config *HashPersistenceProfile) error {
-	return b.post(config, uriLtm, uriPersistence, uriHash)
+	return NewResource[HashPersistenceProfile](b, uriLtm, uriPersistence, uriHash).Create(config)
 }
 
 // DeleteHashPersistenceProfile removes a dest-addr persist profile.
 func (b *BigIP) DeleteHashPersistenceProfile(name string) error {
-	return b.delete(uriLtm, uriPersistence, uriHash, name)
+	return NewResource[HashPersistenceProfile](b, uriLtm, uriPersistence, uriHash).Delete(name)
 }
 
 // ModifyHashPersistenceProfile allows you to change any attribute of a hash persist profile.
 // Fields that can be modified are referenced in the HashPersistenceProfile struct.
 func (b *BigIP) ModifyHashPersistenceProfile(name string, config *HashPersistenceProfile) error {
-	return b.put(config, uriLtm, uriPersistence, uriHash, name)
+	return NewResource[HashPersistenceProfile](b, uriLtm, uriPersistence, uriHash).Update(name, config)
 }
 
 // HostPersistenceProfiles returns a list of host persist profiles
 func (b *BigIP) HostPersistenceProfiles() (*HostPersistenceProfiles, error) {
-	var hostPersistenceProfiles HostPersistenceProfiles
-	err, _ := b.getForEntity(&hostPersistenceProfiles, uriLtm, uriPersistence, uriHost)
+	items, err := NewResource[HostPersistenceProfile](b, uriLtm, uriPersistence, uriHost).List()
 	if err != nil {
 		return nil, err
 	}
 
-	return &hostPersistenceProfiles, nil
+	return &HostPersistenceProfiles{HostPersistenceProfiles: items}, nil
 }
 
 // GetHostPersistenceProfile gets a single host persist profile by name
 func (b *BigIP) GetHostPersistenceProfile(name string) (*HostPersistenceProfile, error) {
-	var hostPersistenceProfile HostPersistenceProfile
-	err, ok := b.getForEntity(&hostPersistenceProfile, uriLtm, uriPersistence, uriHost, name)
-	if err != nil {
-		return nil, err
-	}
-
-	if !ok {
-		return nil, nil
-	}
-
-	return &hostPersistenceProfile, nil
+	return NewResource[HostPersistenceProfile](b, uriLtm, uriPersistence, uriHost).Get(name)
 }
 
 // CreateHostPersistenceProfile creates a new host persist profile on the BIG-IP system.
@@ -3921,49 +3915,38 @@ func (b *BigIP) CreateHostPersistenceProfile(name string, parent string) error {
 		DefaultsFrom: parent,
 	}
 
-	return b.post(config, uriLtm, uriPersistence, uriHost)
+	return NewResource[PersistenceProfile](b, uriLtm, uriPersistence, uriHost).Create(config)
 }
 
 // AddHostPersistenceProfile adds a host persist profile to the BIG-IP system
 func (b *BigIP) AddHostPersistenceProfile(config *HostPersistenceProfile) error {
-	return b.post(config, uriLtm, uriPersistence, uriHost)
+	return NewResource[HostPersistenceProfile](b, uriLtm, uriPersistence, uriHost).Create(config)
 }
 
 // DeleteHashHostPersistenceProfile removes a host persist profile.
 func (b *BigIP) DeleteHashHostPersistenceProfile(name string) error {
-	return b.delete(uriLtm, uriPersistence, uriHost, name)
+	return NewResource[HostPersistenceProfile](b, uriLtm, uriPersistence, uriHost).Delete(name)
 }
 
 // ModifyHostPersistenceProfile allows you to change any attribute of a host persist profile.
 // Fields that can be modified are referenced in the HostPersistenceProfile struct.
 func (b *BigIP) ModifyHostPersistenceProfile(name string, config *HostPersistenceProfile) error {
-	return b.put(config, uriLtm, uriPersistence, uriHost, name)
+	return NewResource[HostPersistenceProfile](b, uriLtm, uriPersistence, uriHost).Update(name, config)
 }
 
 // MSRDPPersistenceProfiles returns a list of msrdp persist profiles
 func (b *BigIP) MSRDPPersistenceProfiles() (*MSRDPPersistenceProfiles, error) {
-	var msrdpPersistenceProfiles MSRDPPersistenceProfiles
-	err, _ := b.getForEntity(&msrdpPersistenceProfiles, uriLtm, uriPersistence, uriMSRDP)
+	items, err := NewResource[MSRDPPersistenceProfile](b, uriLtm, uriPersistence, uriMSRDP).List()
 	if err != nil {
 		return nil, err
 	}
 
-	return &msrdpPersistenceProfiles, nil
+	return &MSRDPPersistenceProfiles{MSRDPPersistenceProfiles: items}, nil
 }
 
 // GetMSRDPPersistenceProfile gets a single msrdp persist profile by name
 func (b *BigIP) GetMSRDPPersistenceProfile(name string) (*MSRDPPersistenceProfile, error) {
-	var msrdpPersistenceProfile MSRDPPersistenceProfile
-	err, ok := b.getForEntity(&msrdpPersistenceProfile, uriLtm, uriPersistence, uriMSRDP, name)
-	if err != nil {
-		return nil, err
-	}
-
-	if !ok {
-		return nil, nil
-	}
-
-	return &msrdpPersistenceProfile, nil
+	return NewResource[MSRDPPersistenceProfile](b, uriLtm, uriPersistence, uriMSRDP).Get(name)
 }
 
 // CreateMSRDPPersistenceProfile creates a new msrdp persist profile on the BIG-IP system.
@@ -3973,49 +3956,38 @@ func (b *BigIP) CreateMSRDPPersistenceProfile(name string, parent string) error
 		DefaultsFrom: parent,
 	}
 
-	return b.post(config, uriLtm, uriPersistence, uriMSRDP)
+	return NewResource[PersistenceProfile](b, uriLtm, uriPersistence, uriMSRDP).Create(config)
 }
 
 // AddMSRDPPersistenceProfile adds a msrdp persist profile to the BIG-IP system
 func (b *BigIP) AddMSRDPPersistenceProfile(config *MSRDPPersistenceProfile) error {
-	return b.post(config, uriLtm, uriPersistence, uriMSRDP)
+	return NewResource[MSRDPPersistenceProfile](b, uriLtm, uriPersistence, uriMSRDP).Create(config)
 }
 
 // DeleteMSRDPPersistenceProfile removes a msrdp persist profile.
 func (b *BigIP) DeleteMSRDPPersistenceProfile(name string) error {
-	return b.delete(uriLtm, uriPersistence, uriMSRDP, name)
+	return NewResource[MSRDPPersistenceProfile](b, uriLtm, uriPersistence, uriMSRDP).Delete(name)
 }
 
 // ModifyMSRDPPersistenceProfile allows you to change any attribute of a msrdp persist profile.
 // Fields that can be modified are referenced in the MSRDPPersistenceProfile struct.
 func (b *BigIP) ModifyMSRDPPersistenceProfile(name string, config *MSRDPPersistenceProfile) error {
-	return b.put(config, uriLtm, uriPersistence, uriMSRDP, name)
+	return NewResource[MSRDPPersistenceProfile](b, uriLtm, uriPersistence, uriMSRDP).Update(name, config)
 }
 
 // SIPPersistenceProfiles returns a list of sip persist profiles
 func (b *BigIP) SIPPersistenceProfiles() (*SIPPersistenceProfiles, error) {
-	var sipPersistenceProfiles SIPPersistenceProfiles
-	err, _ := b.getForEntity(&sipPersistenceProfiles, uriLtm, uriPersistence, uriSIP)
+	items, err := NewResource[SIPPersistenceProfile](b, uriLtm, uriPersistence, uriSIP).List()
 	if err != nil {
 		return nil, err
 	}
 
-	return &sipPersistenceProfiles, nil
+	return &SIPPersistenceProfiles{SIPPersistenceProfiles: items}, nil
 }
 
 // GetSIPPersistenceProfile gets a single sip persist profile by name
 func (b *BigIP) GetSIPPersistenceProfile(name string) (*SIPPersistenceProfile, error) {
-	var sipPersistenceProfile SIPPersistenceProfile
-	err, ok := b.getForEntity(&sipPersistenceProfile, uriLtm, uriPersistence, uriSIP, name)
-	if err != nil {
-		return nil, err
-	}
-
-	if !ok {
-		return nil, nil
-	}
-
-	return &sipPersistenceProfile, nil
+	return NewResource[SIPPersistenceProfile](b, uriLtm, uriPersistence, uriSIP).Get(name)
 }
 
 // CreateSIPPersistenceProfile creates a new sip persist profile on the BIG-IP system.
@@ -4025,49 +3997,38 @@ func (b *BigIP) CreateSIPPersistenceProfile(name string, parent string) error {
 		DefaultsFrom: parent,
 	}
 
-	return b.post(config, uriLtm, uriPersistence, uriSIP)
+	return NewResource[PersistenceProfile](b, uriLtm, uriPersistence, uriSIP).Create(config)
 }
 
 // AddSIPPersistenceProfile adds a sip persist profile to the BIG-IP system
 func (b *BigIP) AddSIPPersistenceProfile(config *SIPPersistenceProfile) error {
-	return b.post(config, uriLtm, uriPersistence, uriSIP)
+	return NewResource[SIPPersistenceProfile](b, uriLtm, uriPersistence, uriSIP).Create(config)
 }
 
 // DeleteSIPPersistenceProfile removes a sip persist profile.
 func (b *BigIP) DeleteSIPPersistenceProfile(name string) error {
-	return b.delete(uriLtm, uriPersistence, uriSIP, name)
+	return NewResource[SIPPersistenceProfile](b, uriLtm, uriPersistence, uriSIP).Delete(name)
 }
 
 // ModifySIPPersistenceProfile allows you to change any attribute of a sip persist profile.
 // Fields that can be modified are referenced in the SIPPersistenceProfile struct.
 func (b *BigIP) ModifySIPPersistenceProfile(name string, config *SIPPersistenceProfile) error {
-	return b.put(config, uriLtm, uriPersistence, uriSIP, name)
+	return NewResource[SIPPersistenceProfile](b, uriLtm, uriPersistence, uriSIP).Update(name, config)
 }
 
 // SourceAddrPersistenceProfiles returns a list of source-addr persist profiles
 func (b *BigIP) SourceAddrPersistenceProfiles() (*SourceAddrPersistenceProfiles, error) {
-	var sourceAddrPersistenceProfiles SourceAddrPersistenceProfiles
-	err, _ := b.getForEntity(&sourceAddrPersistenceProfiles, uriLtm, uriPersistence, uriSourceAddr)
+	items, err := NewResource[SourceAddrPersistenceProfile](b, uriLtm, uriPersistence, uriSourceAddr).List()
 	if err != nil {
 		return nil, err
 	}
 
-	return &sourceAddrPersistenceProfiles, nil
+	return &SourceAddrPersistenceProfiles{SourceAddrPersistenceProfiles: items}, nil
 }
 
 // GetSourceAddrPersistenceProfile gets a single source-addr persist profile by name
 func (b *BigIP) GetSourceAddrPersistenceProfile(name string) (*SourceAddrPersistenceProfile, error) {
-	var sourceAddrPersistenceProfile SourceAddrPersistenceProfile
-	err, ok := b.getForEntity(&sourceAddrPersistenceProfile, uriLtm, uriPersistence, uriSourceAddr, name)
-	if err != nil {
-		return nil, err
-	}
-
-	if !ok {
-		return nil, nil
-	}
-
-	return &sourceAddrPersistenceProfile, nil
+	return NewResource[SourceAddrPersistenceProfile](b, uriLtm, uriPersistence, uriSourceAddr).Get(name)
 }
 
 // CreateSourceAddrPersistenceProfile creates a new source-addr persist profile on the BIG-IP system.
@@ -4077,49 +4038,38 @@ func (b *BigIP) CreateSourceAddrPersistenceProfile(config *PersistenceProfile) e
 		DefaultsFrom: parent,
 	}*/
 
-	return b.post(config, uriLtm, uriPersistence, uriSourceAddr)
+	return NewResource[PersistenceProfile](b, uriLtm, uriPersistence, uriSourceAddr).Create(config)
 }
 
 // AddSourceAddrPersistenceProfile adds a source-addr persist profile to the BIG-IP system
 func (b *BigIP) AddSourceAddrPersistenceProfile(config *SourceAddrPersistenceProfile) error {
-	return b.post(config, uriLtm, uriPersistence, uriSourceAddr)
+	return NewResource[SourceAddrPersistenceProfile](b, uriLtm, uriPersistence, uriSourceAddr).Create(config)
 }
 
 // DeleteSourceAddrPersistenceProfile removes a source-addr persist profile.
 func (b *BigIP) DeleteSourceAddrPersistenceProfile(name string) error {
-	return b.delete(uriLtm, uriPersistence, uriSourceAddr, name)
+	return NewResource[SourceAddrPersistenceProfile](b, uriLtm, uriPersistence, uriSourceAddr).Delete(name)
 }
 
 // ModifySourceAddrPersistenceProfile allows you to change any attribute of a source-addr persist profile.
 // Fields that can be modified are referenced in the SourceAddrPersistenceProfile struct.
 func (b *BigIP) ModifySourceAddrPersistenceProfile(name string, config *SourceAddrPersistenceProfile) error {
-	return b.patch(config, uriLtm, uriPersistence, uriSourceAddr, name)
+	return NewResource[SourceAddrPersistenceProfile](b, uriLtm, uriPersistence, uriSourceAddr).Patch(name, config)
 }
 
 // SSLPersistenceProfiles returns a list of ssl persist profiles
 func (b *BigIP) SSLPersistenceProfiles() (*SSLPersistenceProfiles, error) {
-	var sslPersistenceProfiles SSLPersistenceProfiles
-	err, _ := b.getForEntity(&sslPersistenceProfiles, uriLtm, uriPersistence, uriSSL)
+	items, err := NewResource[SSLPersistenceProfile](b, uriLtm, uriPersistence, uriSSL).List()
 	if err != nil {
 		return nil, err
 	}
 
-	return &sslPersistenceProfiles, nil
+	return &SSLPersistenceProfiles{SSLPersistenceProfiles: items}, nil
 }
 
 // GetSSLPersistenceProfile gets a single ssl persist profile by name
 func (b *BigIP) GetSSLPersistenceProfile(name string) (*SSLPersistenceProfile, error) {
-	var sslPersistenceProfile SSLPersistenceProfile
-	err, ok := b.getForEntity(&sslPersistenceProfile, uriLtm, uriPersistence, uriSSL, name)
-	if err != nil {
-		return nil, err
-	}
-
-	if !ok {
-		return nil, nil
-	}
-
-	return &sslPersistenceProfile, nil
+	return NewResource[SSLPersistenceProfile](b, uriLtm, uriPersistence, uriSSL).Get(name)
 }
 
 // CreateSSLPersistenceProfile creates a new ssl persist profile on the BIG-IP system.
@@ -4129,49 +4079,38 @@ func (b *BigIP) CreateSSLPersistenceProfile(config *PersistenceProfile) error {
 	//		DefaultsFrom: parent,
 	//	}
 
-	return b.post(config, uriLtm, uriPersistence, uriSSL)
+	return NewResource[PersistenceProfile](b, uriLtm, uriPersistence, uriSSL).Create(config)
 }
 
 // AddSSLPersistenceProfile adds a ssl persist profile to the BIG-IP system
 func (b *BigIP) AddSSLPersistenceProfile(config *SSLPersistenceProfile) error {
-	return b.post(config, uriLtm, uriPersistence, uriSSL)
+	return NewResource[SSLPersistenceProfile](b, uriLtm, uriPersistence, uriSSL).Create(config)
 }
 
 // DeleteSSLPersistenceProfile removes a ssl persist profile.
 func (b *BigIP) DeleteSSLPersistenceProfile(name string) error {
-	return b.delete(uriLtm, uriPersistence, uriSSL, name)
+	return NewResource[SSLPersistenceProfile](b, uriLtm, uriPersistence, uriSSL).Delete(name)
 }
 
 // ModifySSLPersistenceProfile allows you to change any attribute of a ssl persist profile.
 // Fields that can be modified are referenced in the SSLPersistenceProfile struct.
 func (b *BigIP) ModifySSLPersistenceProfile(name string, config *SSLPersistenceProfile) error {
-	return b.patch(config, uriLtm, uriPersistence, uriSSL, name)
+	return NewResource[SSLPersistenceProfile](b, uriLtm, uriPersistence, uriSSL).Patch(name, config)
 }
 
 // UniversalPersistenceProfiles returns a list of universal persist profiles
 func (b *BigIP) UniversalPersistenceProfiles() (*UniversalPersistenceProfiles, error) {
-	var universalPersistenceProfiles UniversalPersistenceProfiles
-	err, _ := b.getForEntity(&universalPersistenceProfiles, uriLtm, uriPersistence, uriUniversal)
+	items, err := NewResource[UniversalPersistenceProfile](b, uriLtm, uriPersistence, uriUniversal).List()
 	if err != nil {
 		return nil, err
 	}
 
-	return &universalPersistenceProfiles, nil
+	return &UniversalPersistenceProfiles{SSLPersistenceProfiles: items}, nil
 }
 
 // GetUniversalPersistenceProfile gets a single universal persist profile by name
 func (b *BigIP) GetUniversalPersistenceProfile(name string) (*UniversalPersistenceProfile, error) {
-	var universalPersistenceProfile UniversalPersistenceProfile
-	err, ok := b.getForEntity(&universalPersistenceProfile, uriLtm, uriPersistence, uriUniversal, name)
-	if err != nil {
-		return nil, err
-	}
-
-	if !ok {
-		return nil, nil
-	}
-
-	return &universalPersistenceProfile, nil
+	return NewResource[UniversalPersistenceProfile](b, uriLtm, uriPersistence, uriUniversal).Get(name)
 }
 
 // CreateUniversalPersistenceProfile creates a new universal persist profile on the BIG-IP system.
@@ -4181,48 +4120,37 @@ func (b *BigIP) CreateUniversalPersistenceProfile(name string, parent string) er
 		DefaultsFrom: parent,
 	}
 
-	return b.post(config, uriLtm, uriPersistence, uriUniversal)
+	return NewResource[PersistenceProfile](b, uriLtm, uriPersistence, uriUniversal).Create(config)
 }
 
 // AddUniversalPersistenceProfile adds a universal persist profile to the BIG-IP system
 func (b *BigIP) AddUniversalPersistenceProfile(config *UniversalPersistenceProfile) error {
-	return b.post(config, uriLtm, uriPersistence, uriUniversal)
+	return NewResource[UniversalPersistenceProfile](b, uriLtm, uriPersistence, uriUniversal).Create(config)
 }
 
 // DeleteUniversalPersistenceProfile removes a universal persist profile.
 func (b *BigIP) DeleteUniversalPersistenceProfile(name string) error {
-	return b.delete(uriLtm, uriPersistence, uriUniversal, name)
+	return NewResource[UniversalPersistenceProfile](b, uriLtm, uriPersistence, uriUniversal).Delete(name)
 }
 
 // ModifyUniversalPersistenceProfile allows you to change any attribute of a universal persist profile.
 // Fields that can be modified are referenced in the UniversalPersistenceProfile struct.
 func (b *BigIP) ModifyUniversalPersistenceProfile(name string, config *UniversalPersistenceProfile) error {
-	return b.put(config, uriLtm, uriPersistence, uriUniversal, name)
+	return NewResource[UniversalPersistenceProfile](b, uriLtm, uriPersistence, uriUniversal).Update(name, config)
 }
 
 // HttpProfiles returns a list of HTTP profiles
 func (b *BigIP) HttpProfiles() (*HttpProfiles, error) {
-	var httpProfiles HttpProfiles
-	err, _ := b.getForEntity(&httpProfiles, uriLtm, uriProfile, uriHttp)
+	items, err := NewResource[HttpProfile](b, uriLtm, uriProfile, uriHttp).List()
 	if err != nil {
 		return nil, err
 	}
 
-	return &httpProfiles, nil
+	return &HttpProfiles{HttpProfiles: items}, nil
 }
 
 func (b *BigIP) GetHttpProfile(name string) (*HttpProfile, error) {
-	var httpProfile HttpProfile
-	err, ok := b.getForEntity(&httpProfile, uriLtm, uriProfile, uriHttp, name)
-	if err != nil {
-		return nil, err
-	}
-
-	if !ok {
-		return nil, nil
-	}
-
-	return &httpProfile, nil
+	return NewResource[HttpProfile](b, uriLtm, uriProfile, uriHttp).Get(name)
 }
 
 func (b *BigIP) GetWebAccelerationProfile(name string) (*WebAccelerationProfileService, error) {
@@ -4246,12 +4174,12 @@ func (b *BigIP) CreateHttpProfile(name string, parent string) error {
 		DefaultsFrom: parent,
 	}
 
-	return b.post(config, uriLtm, uriProfile, uriHttp)
+	return NewResource[HttpProfile](b, uriLtm, uriProfile, uriHttp).Create(config)
 }
 
 // AddHttpProfile creates a new http profile on the BIG-IP system.
 func (b *BigIP) AddHttpProfile(config *HttpProfile) error {
-	return b.post(config, uriLtm, uriProfile, uriHttp)
+	return NewResource[HttpProfile](b, uriLtm, uriProfile, uriHttp).Create(config)
 }
 
 // AddWebAcceleration creates a new web acceleration profile service on the BIG-IP system.
@@ -4261,7 +4189,7 @@ func (b *BigIP) AddWebAcceleration(config *WebAccelerationProfileService) error
 
 // DeleteHttpProfile removes a http profile.
 func (b *BigIP) DeleteHttpProfile(name string) error {
-	return b.delete(uriLtm, uriProfile, uriHttp, name)
+	return NewResource[HttpProfile](b, uriLtm, uriProfile, uriHttp).Delete(name)
 }
 
 // DeleteWebAccelerationProfile removes a web acceleration profile.
@@ -4272,7 +4200,7 @@ func (b *BigIP) DeleteWebAccelerationProfile(name string) error {
 // ModifyHttpProfile allows you to change any attribute of a http profile.
 // Fields that can be modified are referenced in the HttpProfile struct.
 func (b *BigIP) ModifyHttpProfile(name string, config *HttpProfile) error {
-	return b.patch(config, uriLtm, uriProfile, uriHttp, name)
+	return NewResource[HttpProfile](b, uriLtm, uriProfile, uriHttp).Patch(name, config)
 }
 
 // ModifyWebAccelerationProfile allows you to change any attribute of a Web Acceleration profile.
@@ -4777,86 +4705,64 @@ func (b *BigIP) GetSaasBotDefenseProfile(name string) (*SaasBotDefenseProfile, e
 
 // UDPProfiles returns a list of UDP profiles
 func (b *BigIP) UDPProfiles() (*UdpProfiles, error) {
-	var udpProfiles UdpProfiles
-	err, _ := b.getForEntity(&udpProfiles, uriLtm, uriProfile, uriUDP)
+	items, err := NewResource[UdpProfile](b, uriLtm, uriProfile, uriUDP).List()
 	if err != nil {
 		return nil, err
 	}
 
-	return &udpProfiles, nil
+	return &UdpProfiles{UdpProfiles: items}, nil
 }
 
 // GetUDPProfile gets a UDP profile by name. Returns nil if the UDP profile does not exist
 func (b *BigIP) GetUDPProfile(name string) (*UdpProfile, error) {
-	var udpProfile UdpProfile
-	err, ok := b.getForEntity(&udpProfile, uriLtm, uriProfile, uriUDP, name)
-	if err != nil {
-		return nil, err
-	}
-
-	if !ok {
-		return nil, nil
-	}
-
-	return &udpProfile, nil
+	return NewResource[UdpProfile](b, uriLtm, uriProfile, uriUDP).Get(name)
 }
 
 // AddUDPProfile creates a new UDP profile on the BIG-IP system.
 func (b *BigIP) AddUDPProfile(config *UdpProfile) error {
-	return b.post(config, uriLtm, uriProfile, uriUDP)
+	return NewResource[UdpProfile](b, uriLtm, uriProfile, uriUDP).Create(config)
 }
 
 // DeleteUDPProfile removes a UDP profile.
 func (b *BigIP) DeleteUDPProfile(name string) error {
-	return b.delete(uriLtm, uriProfile, uriUDP, name)
+	return NewResource[UdpProfile](b, uriLtm, uriProfile, uriUDP).Delete(name)
 }
 
 // ModifyUDPProfile allows you to change any attribute of a UDP profile.
 // Fields that can be modified are referenced in the UdpProfile struct.
 func (b *BigIP) ModifyUDPProfile(name string, config *UdpProfile) error {
-	return b.patch(config, uriLtm, uriProfile, uriUDP, name)
+	return NewResource[UdpProfile](b, uriLtm, uriProfile, uriUDP).Patch(name, config)
 }
 
 // WebsocketProfiles returns a list of websocket profiles.
 func (b *BigIP) WebsocketProfiles() (*WebsocketProfiles, error) {
-	var websocketProfiles WebsocketProfiles
-	err, _ := b.getForEntity(&websocketProfiles, uriLtm, uriProfile, uriWebsocket)
+	items, err := NewResource[WebsocketProfile](b, uriLtm, uriProfile, uriWebsocket).List()
 	if err != nil {
 		return nil, err
 	}
 
-	return &websocketProfiles, nil
+	return &WebsocketProfiles{WebsocketProfiles: items}, nil
 }
 
 // GetWebsocketProfile gets a websocket profile by name. Returns nil if the websocket profile does not exist
 func (b *BigIP) GetWebsocketProfile(name string) (*WebsocketProfile, error) {
-	var websocketProfile WebsocketProfile
-	err, ok := b.getForEntity(&websocketProfile, uriLtm, uriProfile, uriWebsocket, name)
-	if err != nil {
-		return nil, err
-	}
-
-	if !ok {
-		return nil, nil
-	}
-
-	return &websocketProfile, nil
+	return NewResource[WebsocketProfile](b, uriLtm, uriProfile, uriWebsocket).Get(name)
 }
 
 // AddWebsocketProfile creates a new websocket profile on the BIG-IP system.
 func (b *BigIP) AddWebsocketProfile(config *WebsocketProfile) error {
-	return b.post(config, uriLtm, uriProfile, uriWebsocket)
+	return NewResource[WebsocketProfile](b, uriLtm, uriProfile, uriWebsocket).Create(config)
 }
 
 // DeleteWebsocketProfile removes a websocket profile.
 func (b *BigIP) DeleteWebsocketProfile(name string) error {
-	return b.delete(uriLtm, uriProfile, uriWebsocket, name)
+	return NewResource[WebsocketProfile](b, uriLtm, uriProfile, uriWebsocket).Delete(name)
 }
 
 // ModifyWebsocketProfile allows you to change any attribute of a websocket profile.
 // Fields that can be modified are referenced in the WebsocketProfile struct.
 func (b *BigIP) ModifyWebsocketProfile(name string, config *WebsocketProfile) error {
-	return b.patch(config, uriLtm, uriProfile, uriWebsocket, name)
+	return NewResource[WebsocketProfile](b, uriLtm, uriProfile, uriWebsocket).Patch(name, config)
 }
 
 // HTMLProfiles returns a list of html profiles.
@@ -0,0 +1,90 @@
+package bigip
+
+import (
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// Call is a single HTTP round trip made on behalf of an APIRequest. It is
+// handed to every Interceptor in the chain before being sent.
+type Call struct {
+	// APIRequest is the logical request being served. For uploads it
+	// describes the endpoint; the chunk itself is in the HTTP request body.
+	APIRequest *APIRequest
+	// Request is the outgoing HTTP request. Interceptors may add or change
+	// headers, for instance to sign the request.
+	Request *http.Request
+	// Attempt is the 1-based attempt number under the client RetryPolicy.
+	Attempt int
+}
+
+// CallResponse is the raw outcome of a Call.
+type CallResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Duration is the time spent sending the request and reading the body.
+	Duration time.Duration
+}
+
+// CallHandler sends a Call and returns the raw response. A non-nil error
+// means no response was received.
+type CallHandler func(call *Call) (*CallResponse, error)
+
+// Interceptor wraps a CallHandler to add cross-cutting behaviour such as
+// audit logging, metrics or header injection. Interceptors are configured
+// through Config.Interceptors; the first one is the outermost.
+type Interceptor func(next CallHandler) CallHandler
+
+// roundTrip passes call through the configured interceptors and sends it.
+func (b *BigIP) roundTrip(call *Call) (*CallResponse, error) {
+	h := b.send
+	for i := len(b.interceptors) - 1; i >= 0; i-- {
+		h = b.interceptors[i](h)
+	}
+	return h(call)
+}
+
+// send is the innermost CallHandler; it performs the request and reads the body.
+func (b *BigIP) send(call *Call) (*CallResponse, error) {
+	start := time.Now()
+	res, err := b.httpClient().Do(call.Request)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &CallResponse{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       data,
+		Duration:   time.Since(start),
+	}, nil
+}
+
+const redacted = "REDACTED"
+
+var redactBodyRegexp = regexp.MustCompile(`(?i)("(?:password|passphrase|token)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// RedactHeader returns a copy of h with credentials masked, suitable for
+// logging from an Interceptor.
+func RedactHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for _, k := range []string{"Authorization", "X-F5-Auth-Token"} {
+		if out.Get(k) != "" {
+			out.Set(k, redacted)
+		}
+	}
+	return out
+}
+
+// RedactBody returns a copy of a JSON body with password, passphrase and
+// token values masked, suitable for logging from an Interceptor.
+func RedactBody(body []byte) []byte {
+	return redactBodyRegexp.ReplaceAll(body, []byte(`${1}"`+redacted+`"`))
+}
@@ -331,8 +331,9 @@ const (
 // ensure theres a partition for the Resource ID
 func formatResourceID(name string) string {
 	// If the name specifies the partition already, then
-	// just hand it back.
-	regex := regexp.MustCompile(`^~([a-zA-Z0-9-.]+)~`)
+	// just hand it back. A full path such as /Common/name
+	// is turned into ~Common~name by iControlPath.
+	regex := regexp.MustCompile(`^[~/]([a-zA-Z0-9-.]+)[~/]`)
 	if regex.MatchString(name) {
 		return name
 	}
@@ -625,11 +626,14 @@ func (b *BigIP) Tunnels() (*Tunnels, error) {
 	return &tunnels, nil
 }
 
-// GetTunnel fetches the tunnel by it's name.
+// GetTunnel fetches the tunnel by it's name. A name without a partition
+// refers to a tunnel in the Common partition.
 func (b *BigIP) GetTunnel(name string) (*Tunnel, error) {
 	var tunnel Tunnel
-	//result := formatResourceID(name)
-	err, ok := b.getForEntity(&tunnel, uriNet, uriTunnels, uriTunnel, name)
+	// Like GetVxlan, address the tunnel by its partition: a bare name
+	// would refer to a tunnel in whatever partition the user is in.
+	result := formatResourceID(name)
+	err, ok := b.getForEntity(&tunnel, uriNet, uriTunnels, uriTunnel, result)
 	if err != nil {
 		return nil, err
 	}
@@ -1,9 +1,12 @@
 package bigip
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,21 +20,25 @@ type NetTestSuite struct {
 	LastRequest     *http.Request
 	LastRequestBody string
 	ResponseFunc    func(http.ResponseWriter, *http.Request)
+	mu              sync.Mutex
 }
 
 func (s *NetTestSuite) SetupSuite() {
 	s.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
 		s.LastRequestBody = string(body)
 		s.LastRequest = r
+		s.mu.Unlock()
 		if s.ResponseFunc != nil {
 			s.ResponseFunc(w, r)
 		}
 	}))
 	config := &Config{
-		Address:  s.Server.URL,
-		Username: "",
-		Password: "",
+		Address:           s.Server.URL,
+		Username:          "",
+		Password:          "",
+		CertVerifyDisable: true,
 	}
 
 	s.Client = NewSession(config)
@@ -248,7 +255,7 @@ func (s *NetTestSuite) TestVlans() {
 }
 
 func (s *NetTestSuite) TestCreateVLan() {
-	err := s.Client.CreateVlan("name", 1)
+	err := s.Client.CreateVlan(&Vlan{Name: "name", Tag: 1})
 
 	assert.Nil(s.T(), err)
 	assertRestCall(s, "POST", "/mgmt/tm/net/vlan", `{"name":"name", "tag":1, "sflow":{}}`)
@@ -490,6 +497,10 @@ func (s *NetTestSuite) TestGetTunnel() {
 	assertRestCall(s, "GET", "/mgmt/tm/net/tunnels/tunnel/~Common~http-tunnel", "")
 	assert.Equal(s.T(), "http-tunnel", tunnel.Name)
 	assert.Equal(s.T(), "/Common/tcp-forward", tunnel.Profile)
+
+	_, err = s.Client.GetTunnel("/Common/http-tunnel")
+	assert.Nil(s.T(), err)
+	assertRestCall(s, "GET", "/mgmt/tm/net/tunnels/tunnel/~Common~http-tunnel", "")
 }
 
 func (s *NetTestSuite) TestCreateTunnel() {
@@ -665,3 +676,54 @@ func (s *NetTestSuite) TestModifyVxlan() {
 	assert.Nil(s.T(), err)
 	assertRestCall(s, "PUT", "/mgmt/tm/net/tunnels/vxlan/some-foo-vxlan", `{"port":456}`)
 }
+
+// TestConcurrentCalls is meant to be run with -race: a single client is
+// shared by many goroutines reading, writing and opening transactions.
+func (s *NetTestSuite) TestConcurrentCalls() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/mgmt/tm/transaction":
+			w.Write([]byte(`{"transId":1}`))
+		case r.Method == "GET":
+			w.Write([]byte(`{"items":[{"name":"vlan","tag":1}]}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 16; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			vlans, err := s.Client.Vlans()
+			if err == nil && len(vlans.Vlans) != 1 {
+				err = fmt.Errorf("unexpected vlans: %+v", vlans)
+			}
+			errs <- err
+		}()
+		go func(i int) {
+			defer wg.Done()
+			errs <- s.Client.ModifyVlan(fmt.Sprintf("vlan-%d", i), &Vlan{Tag: i})
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, err := s.Client.WithContext(context.Background()).Trunks()
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			t, err := s.Client.StartTransaction()
+			if err == nil {
+				err = s.Client.CommitTransaction(t.TransID)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.Nil(s.T(), err)
+	}
+}
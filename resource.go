@@ -0,0 +1,81 @@
+package bigip
+
+// Resource gives typed CRUD access to an iControl REST collection, such as
+// ltm/profile/http, for any object type T that maps a single item of the
+// collection. It is the building block for the named XProfiles/GetX/AddX/
+// ModifyX/DeleteX functions, and can be used directly for object types that
+// have no dedicated functions yet:
+//
+//	profiles := bigip.NewResource[bigip.HttpProfile](b, "ltm", "profile", "http")
+//	p, err := profiles.Get("/Common/http")
+//
+// Requests are bound to the context of the client, so use a client derived
+// with WithContext to bound them.
+type Resource[T any] struct {
+	b    *BigIP
+	path []string
+}
+
+// NewResource returns a Resource for the collection at path, given as
+// segments relative to mgmt/tm, e.g. "ltm", "profile", "http".
+func NewResource[T any](b *BigIP, path ...string) *Resource[T] {
+	return &Resource[T]{b: b, path: path}
+}
+
+// itemPath returns the path of the object called name. Names may be given
+// with their partition, e.g. "/Common/http".
+func (r *Resource[T]) itemPath(name string) []string {
+	return append(r.path[:len(r.path):len(r.path)], name)
+}
+
+// List returns every object in the collection.
+func (r *Resource[T]) List() ([]T, error) {
+	var list struct {
+		Items []T `json:"items"`
+	}
+	err, _ := r.b.getForEntity(&list, r.path...)
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// Get returns the object called name. If it does not exist the error
+// satisfies IsNotFound.
+func (r *Resource[T]) Get(name string) (*T, error) {
+	var obj T
+	err, _ := r.b.getForEntity(&obj, r.itemPath(name)...)
+	if err != nil {
+		return nil, err
+	}
+	return &obj, nil
+}
+
+// Exists reports whether the object called name exists.
+func (r *Resource[T]) Exists(name string) (bool, error) {
+	_, err := r.Get(name)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Create adds obj to the collection.
+func (r *Resource[T]) Create(obj *T) error {
+	return r.b.post(obj, r.path...)
+}
+
+// Update replaces the object called name with obj.
+func (r *Resource[T]) Update(name string, obj *T) error {
+	return r.b.put(obj, r.itemPath(name)...)
+}
+
+// Patch changes the attributes of the object called name that are set in obj.
+func (r *Resource[T]) Patch(name string, obj *T) error {
+	return r.b.patch(obj, r.itemPath(name)...)
+}
+
+// Delete removes the object called name.
+func (r *Resource[T]) Delete(name string) error {
+	return r.b.delete(r.itemPath(name)...)
+}
@@ -0,0 +1,132 @@
+package bigip
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryAttempt describes an API call attempt that has just failed.
+type RetryAttempt struct {
+	// Attempt is the 1-based number of the attempt that failed.
+	Attempt int
+	// Method is the upper-case HTTP method of the request.
+	Method string
+	// Idempotent is set for requests that can be replayed safely although
+	// their method is not idempotent, such as upload chunks: POSTs that
+	// rewrite the same byte range.
+	Idempotent bool
+	// StatusCode is the HTTP status of the response, or 0 when the request
+	// failed before a response was received.
+	StatusCode int
+	// Err is the transport error, or the *APIError built from the response.
+	Err error
+	// RetryAfter is the delay requested by the device through the
+	// Retry-After header, or 0 when it sent none.
+	RetryAfter time.Duration
+}
+
+// RetryPolicy decides whether a failed API call is attempted again and how
+// long to wait before doing so. Set one on ConfigOptions.RetryPolicy to
+// replace the default ExponentialBackoff.
+type RetryPolicy interface {
+	ShouldRetry(attempt *RetryAttempt) (delay time.Duration, retry bool)
+}
+
+// ExponentialBackoff is the default RetryPolicy. It retries responses that
+// mean the device did not process the request (429, 503 and the AS3 "active
+// asynchronous task" error) for any method, and transport errors and 502/504
+// only for idempotent methods, so that a POST is never replayed when it may
+// already have been applied. The delay doubles with every attempt, is capped
+// at MaxDelay and jittered, unless the device sent a Retry-After header.
+type ExponentialBackoff struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. Defaults to one second.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed delay. Defaults to thirty seconds.
+	MaxDelay time.Duration
+}
+
+// ShouldRetry implements RetryPolicy.
+func (p *ExponentialBackoff) ShouldRetry(a *RetryAttempt) (time.Duration, bool) {
+	if a.Attempt >= p.MaxAttempts || !isRetryable(a) {
+		return 0, false
+	}
+	if a.RetryAfter > 0 {
+		return a.RetryAfter, true
+	}
+	base, maxDelay := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = time.Second
+	}
+	if maxDelay <= 0 {
+		maxDelay = 30 * time.Second
+	}
+	delay := maxDelay
+	if shift := a.Attempt - 1; shift < 32 && base<<shift < maxDelay {
+		delay = base << shift
+	}
+	// Equal jitter: keep half of the delay, randomise the other half.
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1)), true
+}
+
+func isRetryable(a *RetryAttempt) bool {
+	if errors.Is(a.Err, context.Canceled) || errors.Is(a.Err, context.DeadlineExceeded) {
+		return false
+	}
+	if apiErr, ok := asAPIError(a.Err); ok {
+		if apiErr.hasStatus(http.StatusTooManyRequests) || apiErr.hasStatus(http.StatusServiceUnavailable) {
+			return true
+		}
+		// With how some of the requests come back from AS3, we sometimes have a nested error, so check the entire message for the "active asynchronous task" error
+		if strings.Contains(strings.ToLower(apiErr.Message), "there is an active asynchronous task executing") {
+			return true
+		}
+		switch apiErr.StatusCode {
+		case http.StatusBadGateway, http.StatusGatewayTimeout:
+			return a.Idempotent || isIdempotent(a.Method)
+		}
+		return false
+	}
+	// Transport error: the request may or may not have reached the device.
+	return a.Err != nil && (a.Idempotent || isIdempotent(a.Method))
+}
+
+func isIdempotent(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryPolicy returns the configured RetryPolicy, or an ExponentialBackoff
+// bounded by ConfigOptions.APICallRetries.
+func (b *BigIP) retryPolicy() RetryPolicy {
+	if b.ConfigOptions.RetryPolicy != nil {
+		return b.ConfigOptions.RetryPolicy
+	}
+	return &ExponentialBackoff{MaxAttempts: b.ConfigOptions.APICallRetries}
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(header http.Header) time.Duration {
+	v := header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
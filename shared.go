@@ -2,22 +2,22 @@ package bigip
 
 import (
 	"bytes"
-	"fmt"
 	"os"
-	"strings"
 )
 
 const (
-	uriShared            = "shared"
-	uriLicensing         = "licensing"
-	uriActivation        = "activation"
-	uriRegistration      = "registration"
-	uriFileTransfer      = "file-transfer"
-	uriUploads           = "uploads"
-	activationComplete   = "LICENSING_COMPLETE"
-	activationInProgress = "LICENSING_ACTIVATION_IN_PROGRESS"
-	activationFailed     = "LICENSING_FAILED"
-	activationNeedEula   = "NEED_EULA_ACCEPT"
+	uriShared               = "shared"
+	uriLicensing            = "licensing"
+	uriActivation           = "activation"
+	uriRegistration         = "registration"
+	uriFileTransfer         = "file-transfer"
+	uriUploads              = "uploads"
+	uriAutodeploy           = "autodeploy"
+	uriSoftwareImageUploads = "software-image-uploads"
+	activationComplete      = "LICENSING_COMPLETE"
+	activationInProgress    = "LICENSING_ACTIVATION_IN_PROGRESS"
+	activationFailed        = "LICENSING_FAILED"
+	activationNeedEula      = "NEED_EULA_ACCEPT"
 )
 
 // Installs the given license.
@@ -32,17 +32,10 @@ func (b *BigIP) RevokeLicense() error {
 	return b.delete(uriShared, uriLicensing, uriRegistration)
 }
 
-// Upload a file
+// Upload a file. Software images (.iso) go to the software image store,
+// /shared/images, and other files to /var/config/rest/downloads.
 func (b *BigIP) UploadFile(f *os.File) (*Upload, error) {
-	if strings.HasSuffix(f.Name(), ".iso") {
-		err := fmt.Errorf("File must not have .iso extension")
-		return nil, err
-	}
-	info, err := f.Stat()
-	if err != nil {
-		return nil, err
-	}
-	return b.Upload(f, info.Size(), uriShared, uriFileTransfer, uriUploads, info.Name())
+	return b.UploadFileWithOptions(b.context(), f, nil)
 }
 
 // Upload a file from a byte slice
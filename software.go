@@ -0,0 +1,341 @@
+package bigip
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	uriSoftware       = "software"
+	uriImage          = "image"
+	uriHotfix         = "hotfix"
+	uriVolume         = "volume"
+	softwareImagesDir = "/shared/images"
+)
+
+// softwarePollInterval is the delay between two checks while an image is
+// installed or the device reboots.
+var softwarePollInterval = 10 * time.Second
+
+// volumeName matches the installation volumes of the device, such as HD1.2.
+var volumeName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// SoftwareImage is a software image (ISO) available on the device, in
+// /shared/images.
+type SoftwareImage struct {
+	Name         string `json:"name,omitempty"`
+	FullPath     string `json:"fullPath,omitempty"`
+	Build        string `json:"build,omitempty"`
+	Checksum     string `json:"checksum,omitempty"`
+	FileSize     string `json:"fileSize,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Product      string `json:"product,omitempty"`
+	Verified     string `json:"verified,omitempty"`
+	Version      string `json:"version,omitempty"`
+}
+
+// SoftwareHotfix is a hotfix image available on the device.
+type SoftwareHotfix struct {
+	Name         string `json:"name,omitempty"`
+	FullPath     string `json:"fullPath,omitempty"`
+	Build        string `json:"build,omitempty"`
+	Checksum     string `json:"checksum,omitempty"`
+	FileSize     string `json:"fileSize,omitempty"`
+	ID           string `json:"id,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Product      string `json:"product,omitempty"`
+	Title        string `json:"title,omitempty"`
+	Verified     string `json:"verified,omitempty"`
+	Version      string `json:"version,omitempty"`
+}
+
+// SoftwareVolume is an installation volume (boot location) of the device.
+type SoftwareVolume struct {
+	Name      string `json:"name,omitempty"`
+	FullPath  string `json:"fullPath,omitempty"`
+	Active    bool   `json:"active,omitempty"`
+	BaseBuild string `json:"basebuild,omitempty"`
+	Build     string `json:"build,omitempty"`
+	Product   string `json:"product,omitempty"`
+	// Status is "complete" once an installation has succeeded, and reports
+	// the progress, such as "installing 42.000 pct", or the failure otherwise.
+	Status  string `json:"status,omitempty"`
+	Version string `json:"version,omitempty"`
+	Media   []struct {
+		Name                string `json:"name,omitempty"`
+		DefaultBootLocation bool   `json:"defaultBootLocation,omitempty"`
+		Label               string `json:"label,omitempty"`
+		Media               string `json:"media,omitempty"`
+	} `json:"media,omitempty"`
+}
+
+// softwareInstall is the body of the install command of sys/software.
+type softwareInstall struct {
+	Command string              `json:"command"`
+	Name    string              `json:"name"`
+	Volume  string              `json:"volume"`
+	Options []map[string]string `json:"options,omitempty"`
+}
+
+// SoftwareImages returns the software images available on the device.
+func (b *BigIP) SoftwareImages() ([]SoftwareImage, error) {
+	return NewResource[SoftwareImage](b, uriSys, uriSoftware, uriImage).List()
+}
+
+// GetSoftwareImage returns the software image called name, such as
+// "BIGIP-17.1.0-0.0.16.iso".
+func (b *BigIP) GetSoftwareImage(name string) (*SoftwareImage, error) {
+	return NewResource[SoftwareImage](b, uriSys, uriSoftware, uriImage).Get(name)
+}
+
+// DeleteSoftwareImage removes the software image called name.
+func (b *BigIP) DeleteSoftwareImage(name string) error {
+	return NewResource[SoftwareImage](b, uriSys, uriSoftware, uriImage).Delete(name)
+}
+
+// SoftwareHotfixes returns the hotfix images available on the device.
+func (b *BigIP) SoftwareHotfixes() ([]SoftwareHotfix, error) {
+	return NewResource[SoftwareHotfix](b, uriSys, uriSoftware, uriHotfix).List()
+}
+
+// SoftwareVolumes returns the installation volumes of the device.
+func (b *BigIP) SoftwareVolumes() ([]SoftwareVolume, error) {
+	return NewResource[SoftwareVolume](b, uriSys, uriSoftware, uriVolume).List()
+}
+
+// GetSoftwareVolume returns the installation volume called name, such as
+// "HD1.2". If it does not exist the error satisfies IsNotFound.
+func (b *BigIP) GetSoftwareVolume(name string) (*SoftwareVolume, error) {
+	return NewResource[SoftwareVolume](b, uriSys, uriSoftware, uriVolume).Get(name)
+}
+
+// UploadSoftwareImage uploads the software image f to /shared/images and
+// returns it once the device lists it. The MD5 of the file on the device is
+// checked against that of the bytes read; set opts.Verify to check the
+// SHA-256 too.
+func (b *BigIP) UploadSoftwareImage(ctx context.Context, f *os.File, opts *UploadOptions) (*SoftwareImage, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	name := info.Name()
+	if !strings.HasSuffix(name, ".iso") {
+		return nil, fmt.Errorf("software image %s must have the .iso extension", name)
+	}
+	h := md5.New()
+	if _, err := b.UploadWithOptions(ctx, io.TeeReader(f, h), info.Size(), opts, uriCm, uriAutodeploy, uriSoftwareImageUploads, name); err != nil {
+		return nil, err
+	}
+	if err := b.verifySoftwareImageMD5(ctx, name, hex.EncodeToString(h.Sum(nil))); err != nil {
+		return nil, err
+	}
+
+	// The device lists the image once it has read its metadata.
+	var image *SoftwareImage
+	client := b.WithContext(ctx)
+	err = poll(ctx, softwarePollInterval, func(ctx context.Context) (bool, error) {
+		image, err = client.GetSoftwareImage(name)
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("software image %s not listed: %w", name, err)
+	}
+	return image, nil
+}
+
+// VerifySoftwareImageMD5 checks that the software image called name has the
+// hex MD5 digest md5sum, as published alongside the image. It returns an
+// error wrapping ErrChecksumMismatch if not.
+func (b *BigIP) VerifySoftwareImageMD5(name, md5sum string) error {
+	return b.verifySoftwareImageMD5(b.context(), name, md5sum)
+}
+
+func (b *BigIP) verifySoftwareImageMD5(ctx context.Context, name, md5sum string) error {
+	path := softwareImagesDir + "/" + name
+	if err := checkShellPath(path); err != nil {
+		return err
+	}
+	out, err := b.runBash(ctx, fmt.Sprintf("md5sum '%s'", path))
+	if err != nil {
+		return fmt.Errorf("failed to verify %s: %w", name, err)
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 || len(fields[0]) != md5.Size*2 {
+		return fmt.Errorf("failed to verify %s: %s", name, strings.TrimSpace(out))
+	}
+	if !strings.EqualFold(fields[0], md5sum) {
+		return fmt.Errorf("%w: %s has MD5 %s, want %s", ErrChecksumMismatch, name, fields[0], md5sum)
+	}
+	return nil
+}
+
+// InstallSoftwareImage starts installing the software image called name to
+// the volume, such as "HD1.2", creating the volume if needed. Use
+// WaitForSoftwareInstall to follow the installation.
+func (b *BigIP) InstallSoftwareImage(name, volume string) error {
+	return b.installSoftware(uriImage, name, volume)
+}
+
+// InstallSoftwareHotfix starts installing the hotfix called name to the
+// volume, which must already hold the matching base image.
+func (b *BigIP) InstallSoftwareHotfix(name, volume string) error {
+	return b.installSoftware(uriHotfix, name, volume)
+}
+
+func (b *BigIP) installSoftware(kind, name, volume string) error {
+	cmd := &softwareInstall{Command: "install", Name: name, Volume: volume}
+	_, err := b.GetSoftwareVolume(volume)
+	switch {
+	case IsNotFound(err):
+		cmd.Options = append(cmd.Options, map[string]string{"create-volume": ""})
+	case err != nil:
+		return err
+	}
+	b.log().Info("installing software", "image", name, "volume", volume)
+	return b.post(cmd, uriSys, uriSoftware, kind)
+}
+
+// WaitForSoftwareInstall polls the volume until the installation in progress
+// completes, and returns the volume. It fails as soon as the device reports
+// the installation as failed.
+func (b *BigIP) WaitForSoftwareInstall(ctx context.Context, volume string) (*SoftwareVolume, error) {
+	var v *SoftwareVolume
+	var status string
+	client := b.WithContext(ctx)
+	err := poll(ctx, softwarePollInterval, func(ctx context.Context) (bool, error) {
+		var err error
+		v, err = client.GetSoftwareVolume(volume)
+		if err != nil {
+			// The volume shows up once the installation has started.
+			return false, err
+		}
+		if v.Status != status {
+			status = v.Status
+			b.log().Info("software installation", "volume", volume, "status", status)
+		}
+		switch {
+		case v.Status == "complete":
+			return true, nil
+		case strings.HasPrefix(v.Status, "failed"):
+			return true, fmt.Errorf("installation to %s failed: %s", volume, v.Status)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// SetBootLocation makes the device boot from volume, such as "HD1.2", at
+// its next restart.
+func (b *BigIP) SetBootLocation(volume string) error {
+	if !volumeName.MatchString(volume) {
+		return fmt.Errorf("invalid volume %q", volume)
+	}
+	out, err := b.runBash(b.context(), "switchboot -b "+volume)
+	if err != nil {
+		return err
+	}
+	if out = strings.TrimSpace(out); out != "" && !strings.Contains(out, volume) {
+		return fmt.Errorf("failed to set boot location to %s: %s", volume, out)
+	}
+	return nil
+}
+
+// Reboot restarts the device, into volume if it is set and into the current
+// boot location otherwise. The device may drop the connection once it has
+// received the request, which is not reported as an error; use
+// WaitForVersion to wait for the device to come back.
+func (b *BigIP) Reboot(volume string) error {
+	cmd := map[string]string{"command": "reboot"}
+	if volume != "" {
+		cmd["volume"] = volume
+	}
+	err := b.post(cmd, uriSys)
+	if connectionDropped(err) {
+		b.log().Warn("reboot request interrupted", "error", err)
+		return nil
+	}
+	return err
+}
+
+// connectionDropped reports whether err tells that the device closed the
+// connection after the request was sent, rather than that it could not be
+// reached.
+func connectionDropped(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// softwareRestartTimeout bounds the wait for the device to begin restarting
+// in WaitForVersion.
+var softwareRestartTimeout = 5 * time.Minute
+
+// WaitForVersion waits, after a reboot, until the device has restarted and
+// runs version, such as "17.1.0", and build, such as "0.0.16", and is
+// ready. Both are compared exactly with those reported by sys/version; an
+// empty build matches any build.
+//
+// The device is first polled until it stops answering or reports another
+// version than when the wait began, so that the wait does not end before
+// the reboot; if neither happens within five minutes, the reboot is taken
+// to be over already.
+func (b *BigIP) WaitForVersion(ctx context.Context, version, build string) error {
+	client := b.WithContext(ctx)
+	restartCtx, cancel := context.WithTimeout(ctx, softwareRestartTimeout)
+	defer cancel()
+	var before string
+	err := poll(restartCtx, softwarePollInterval, func(ctx context.Context) (bool, error) {
+		running, runningBuild, err := client.WithContext(ctx).runningVersion()
+		if err != nil {
+			return true, nil
+		}
+		if before == "" {
+			before = running + " " + runningBuild
+		}
+		return running+" "+runningBuild != before, nil
+	})
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return fmt.Errorf("device not running version %s: %w", version, ctx.Err())
+	case err != nil:
+		b.log().Warn("device did not restart", "timeout", softwareRestartTimeout)
+	}
+
+	err = poll(ctx, softwarePollInterval, func(ctx context.Context) (bool, error) {
+		running, runningBuild, err := client.WithContext(ctx).runningVersion()
+		if err != nil {
+			return false, err
+		}
+		if running != version || (build != "" && runningBuild != build) {
+			b.log().Debug("waiting for version", "running", running, "build", runningBuild, "version", version)
+			return false, nil
+		}
+		return b.ready(ctx, "")
+	})
+	if err != nil {
+		return fmt.Errorf("device not running version %s: %w", version, err)
+	}
+	return nil
+}
+
+// runningVersion returns the version and build the device runs.
+func (b *BigIP) runningVersion() (version, build string, err error) {
+	stats, err := b.getObjectStats(uriSys, uriVersion)
+	if err != nil {
+		return "", "", err
+	}
+	return stats.Descriptions["Version"], stats.Descriptions["Build"], nil
+}
@@ -0,0 +1,199 @@
+package bigip
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	uriHostInfo    = "host-info"
+	uriPerformance = "performance"
+	uriAllStats    = "all-stats"
+)
+
+// Stats holds the statistics of a single object, flattened from the
+// entries/nestedStats/entries shape iControl REST reports them in.
+type Stats struct {
+	// Name identifies the object, such as "/Common/vs" for a virtual server,
+	// "/Common/web:80" for a pool member or "0" for sys/host-info.
+	Name string
+	// Values holds the counters, such as "clientside.bitsIn".
+	Values map[string]int64
+	// Descriptions holds the textual fields, such as
+	// "status.availabilityState".
+	Descriptions map[string]string
+	// Nested holds the nested statistics, such as the "cpuInfo" of
+	// sys/host-info, by the name of the nested collection, then by object.
+	Nested map[string]map[string]Stats
+}
+
+type statsEntry struct {
+	Value       *json.Number `json:"value"`
+	Description *string      `json:"description"`
+	NestedStats *statsBody   `json:"nestedStats"`
+}
+
+type statsBody struct {
+	SelfLink string                `json:"selfLink"`
+	Entries  map[string]statsEntry `json:"entries"`
+}
+
+// GetStats returns the statistics at path, by object. path is relative to
+// mgmt/tm and includes the stats endpoint, for instance
+// GetStats("ltm", "virtual", "stats") for every virtual server or
+// GetStats("sys", "performance", "all-stats").
+func (b *BigIP) GetStats(path ...string) (map[string]Stats, error) {
+	var body statsBody
+	err, _ := b.getForEntity(&body, path...)
+	if err != nil {
+		return nil, err
+	}
+	return decodeStats(&body)
+}
+
+// getObjectStats returns the statistics at path, which belong to a single
+// object.
+func (b *BigIP) getObjectStats(path ...string) (*Stats, error) {
+	stats, err := b.GetStats(path...)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range stats {
+		return &s, nil
+	}
+	return nil, fmt.Errorf("no statistics returned for %s", b.iControlPath(path))
+}
+
+// decodeStats flattens body. Each of its entries is an object, keyed by the
+// link to its statistics; versions before 12.0 report the statistics of a
+// single object directly instead.
+func decodeStats(body *statsBody) (map[string]Stats, error) {
+	for _, entry := range body.Entries {
+		if entry.NestedStats == nil {
+			s, err := decodeStatsObject(body.SelfLink, body.Entries)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]Stats{s.Name: s}, nil
+		}
+	}
+	objects := map[string]Stats{}
+	for key, entry := range body.Entries {
+		s, err := decodeStatsObject(key, entry.NestedStats.Entries)
+		if err != nil {
+			return nil, err
+		}
+		objects[s.Name] = s
+	}
+	return objects, nil
+}
+
+func decodeStatsObject(link string, entries map[string]statsEntry) (Stats, error) {
+	s := Stats{
+		Name:         statsName(link),
+		Values:       map[string]int64{},
+		Descriptions: map[string]string{},
+	}
+	for key, entry := range entries {
+		switch {
+		case entry.NestedStats != nil:
+			nested, err := decodeStats(entry.NestedStats)
+			if err != nil {
+				return s, err
+			}
+			if s.Nested == nil {
+				s.Nested = map[string]map[string]Stats{}
+			}
+			s.Nested[statsName(key)] = nested
+		case entry.Value != nil:
+			v, err := entry.Value.Int64()
+			if err != nil {
+				// Fractional values are truncated, and counters beyond the
+				// int64 range saturate.
+				f, ferr := strconv.ParseFloat(entry.Value.String(), 64)
+				if ferr != nil {
+					return s, fmt.Errorf("invalid value %q for %s: %w", entry.Value.String(), key, err)
+				}
+				v = math.MaxInt64
+				if f < math.MaxInt64 {
+					v = int64(f)
+				}
+			}
+			s.Values[key] = v
+		case entry.Description != nil:
+			s.Descriptions[key] = *entry.Description
+		}
+	}
+	return s, nil
+}
+
+// statsName returns the name of the object a statistics link refers to,
+// e.g. "/Common/vs" for
+// "https://localhost/mgmt/tm/ltm/virtual/~Common~vs/stats?ver=17.1.0".
+func statsName(link string) string {
+	if i := strings.IndexByte(link, '?'); i >= 0 {
+		link = link[:i]
+	}
+	link = strings.TrimSuffix(strings.TrimSuffix(link, "/"), "/stats")
+	name := link[strings.LastIndexByte(link, '/')+1:]
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return strings.ReplaceAll(name, "~", "/")
+}
+
+// VirtualServerStats returns the statistics of the virtual server called
+// name, such as "/Common/vs".
+func (b *BigIP) VirtualServerStats(name string) (*Stats, error) {
+	return b.getObjectStats(uriLtm, uriVirtual, name, uriStats)
+}
+
+// PoolStats returns the statistics of the pool called name.
+func (b *BigIP) PoolStats(name string) (*Stats, error) {
+	return b.getObjectStats(uriLtm, uriPool, name, uriStats)
+}
+
+// PoolMemberStats returns the statistics of the members of pool, by member
+// name, such as "/Common/web:80".
+func (b *BigIP) PoolMemberStats(pool string) (map[string]Stats, error) {
+	return b.GetStats(uriLtm, uriPool, pool, uriPoolMember, uriStats)
+}
+
+// NodeStats returns the statistics of every node, by node name.
+func (b *BigIP) NodeStats() (map[string]Stats, error) {
+	return b.GetStats(uriLtm, uriNode, uriStats)
+}
+
+// InterfaceStats returns the statistics of every interface, by interface
+// name, such as "1.1".
+func (b *BigIP) InterfaceStats() (map[string]Stats, error) {
+	return b.GetStats(uriNet, uriInterface, uriStats)
+}
+
+// VlanStats returns the statistics of every VLAN, by VLAN name.
+func (b *BigIP) VlanStats() (map[string]Stats, error) {
+	return b.GetStats(uriNet, uriVlan, uriStats)
+}
+
+// TrunkStats returns the statistics of every trunk, by trunk name.
+func (b *BigIP) TrunkStats() (map[string]Stats, error) {
+	return b.GetStats(uriNet, uriTrunk, uriStats)
+}
+
+// HostInfoStats returns the CPU and memory statistics of the device, by
+// host, "0" on an appliance. The statistics of each CPU are nested under
+// "cpuInfo".
+func (b *BigIP) HostInfoStats() (map[string]Stats, error) {
+	return b.GetStats(uriSys, uriHostInfo)
+}
+
+// PerformanceStats returns the performance graphs of the device, such as
+// "Utilization" or "Active Connections", with their "Current", "Average"
+// and "Max" figures as descriptions.
+func (b *BigIP) PerformanceStats() (map[string]Stats, error) {
+	return b.GetStats(uriSys, uriPerformance, uriAllStats)
+}
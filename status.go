@@ -0,0 +1,149 @@
+package bigip
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// statusPollInterval is the delay between two checks while waiting for an
+// object to reach a state.
+var statusPollInterval = time.Second
+
+// Availability is the effective state of an LTM object, as derived by the
+// device from its monitors and admin state. NodeStatus, PoolMemberStatus and
+// VirtualAddressStatus set the admin state; these functions read back the
+// outcome.
+type Availability struct {
+	Name string
+	// AvailabilityState is "available", "offline", "unknown" (no monitor) or
+	// "unavailable".
+	AvailabilityState string
+	// EnabledState is "enabled", "disabled" or "disabled-by-parent".
+	EnabledState string
+	// StatusReason explains the state, for instance "Pool member has been
+	// marked down by a monitor".
+	StatusReason string
+}
+
+// Available reports whether the object is up and enabled, and so takes new
+// traffic.
+func (a *Availability) Available() bool {
+	return a.AvailabilityState == "available" && a.EnabledState == "enabled"
+}
+
+// is reports whether the object is in state, either an availability state or
+// an enabled state.
+func (a *Availability) is(state string) bool {
+	return a.AvailabilityState == state || a.EnabledState == state
+}
+
+func availabilityOf(s *Stats) *Availability {
+	return &Availability{
+		Name:              s.Name,
+		AvailabilityState: s.Descriptions["status.availabilityState"],
+		EnabledState:      s.Descriptions["status.enabledState"],
+		StatusReason:      s.Descriptions["status.statusReason"],
+	}
+}
+
+func (b *BigIP) availability(path ...string) (*Availability, error) {
+	s, err := b.getObjectStats(path...)
+	if err != nil {
+		return nil, err
+	}
+	return availabilityOf(s), nil
+}
+
+// NodeAvailability returns the effective state of the node called name.
+func (b *BigIP) NodeAvailability(name string) (*Availability, error) {
+	return b.availability(uriLtm, uriNode, name, uriStats)
+}
+
+// PoolAvailability returns the effective state of the pool called name.
+func (b *BigIP) PoolAvailability(name string) (*Availability, error) {
+	return b.availability(uriLtm, uriPool, name, uriStats)
+}
+
+// PoolMemberAvailability returns the effective state of member, in the form
+// <node>:<port>, i.e. "/Common/web-server1:443", of pool.
+func (b *BigIP) PoolMemberAvailability(pool, member string) (*Availability, error) {
+	return b.availability(uriLtm, uriPool, pool, uriPoolMember, member, uriStats)
+}
+
+// VirtualServerAvailability returns the effective state of the virtual
+// server called name.
+func (b *BigIP) VirtualServerAvailability(name string) (*Availability, error) {
+	return b.availability(uriLtm, uriVirtual, name, uriStats)
+}
+
+// VirtualAddressAvailability returns the effective state of the virtual
+// address called name.
+func (b *BigIP) VirtualAddressAvailability(name string) (*Availability, error) {
+	return b.availability(uriLtm, uriVirtualAddress, name, uriStats)
+}
+
+// PoolHealth summarizes the state of a pool and its members.
+type PoolHealth struct {
+	Pool Availability
+	// Members holds the state of each member, sorted by name.
+	Members []Availability
+	// Available counts the members that are up and enabled, Offline those
+	// that are down, Disabled those that are up but disabled, and Unknown
+	// those that are not monitored.
+	Available, Offline, Disabled, Unknown int
+}
+
+// PoolHealth returns the state of the pool called name and of its members.
+func (b *BigIP) PoolHealth(name string) (*PoolHealth, error) {
+	pool, err := b.PoolAvailability(name)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := b.PoolMemberStats(name)
+	if err != nil {
+		return nil, err
+	}
+	health := &PoolHealth{Pool: *pool, Members: make([]Availability, 0, len(stats))}
+	for _, s := range stats {
+		a := availabilityOf(&s)
+		health.Members = append(health.Members, *a)
+		switch {
+		case a.Available():
+			health.Available++
+		case a.AvailabilityState == "offline" || a.AvailabilityState == "unavailable":
+			health.Offline++
+		case a.AvailabilityState == "available":
+			health.Disabled++
+		default:
+			health.Unknown++
+		}
+	}
+	sort.Slice(health.Members, func(i, j int) bool { return health.Members[i].Name < health.Members[j].Name })
+	return health, nil
+}
+
+// WaitForPoolMemberState polls member of pool until it reaches desired,
+// either an availability state ("available", "offline", "unknown") or an
+// enabled state ("enabled", "disabled"), and returns its state. It gives up
+// when ctx is done.
+func (b *BigIP) WaitForPoolMemberState(ctx context.Context, pool, member, desired string) (*Availability, error) {
+	var a *Availability
+	client := b.WithContext(ctx)
+	err := poll(ctx, statusPollInterval, func(ctx context.Context) (bool, error) {
+		var err error
+		a, err = client.PoolMemberAvailability(pool, member)
+		if err != nil {
+			return IsNotFound(err), err
+		}
+		return a.is(desired), nil
+	})
+	if err != nil {
+		if a != nil {
+			return a, fmt.Errorf("pool member %s of %s is %s/%s, not %s: %w", member, pool, a.AvailabilityState, a.EnabledState, desired, err)
+		}
+		return nil, err
+	}
+	return a, nil
+}
@@ -11,13 +11,12 @@ See the License for the specific language governing permissions and limitations
 package bigip
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"strconv"
-
-	//"strings"
+	"strings"
 	"time"
 )
 
@@ -440,6 +439,17 @@ func (b *BigIP) Certificates() (*Certificates, error) {
 	return &certs, nil
 }
 
+// CertificatesWithOptions returns the certificates selected by opts, following every page
+// of the listing.
+func (b *BigIP) CertificatesWithOptions(opts *ListOptions) (*Certificates, error) {
+	items, err := NewResource[Certificate](b, uriSys, uriFile, uriSslCert).ListAll(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Certificates{Certificates: items}, nil
+}
+
 // AddCertificate installs a certificate.
 func (b *BigIP) AddCertificate(cert *Certificate) error {
 	return b.post(cert, uriSys, uriFile, uriSslCert)
@@ -473,10 +483,8 @@ func (b *BigIP) UploadCertificate(certpath string, cert *Certificate) error {
 		return err
 	}
 	sourcepath := "file://" + REST_DOWNLOAD_PATH + "/" + cert.Name
-	log.Printf("[DEBUG] sourcepath :%+v", sourcepath)
-
 	cert.SourcePath = sourcepath
-	log.Printf("cert: %+v\n", cert)
+	b.log().Debug("adding uploaded certificate", "certificate", cert.Name, "partition", cert.Partition, "source_path", sourcepath)
 	err = b.AddCertificate(cert)
 	if err != nil {
 		return err
@@ -514,7 +522,7 @@ func (b *BigIP) UpdateCertificate(certpath string, cert *Certificate) error {
 
 	cert.SourcePath = sourcepath
 	certName := fmt.Sprintf("/%s/%s", cert.Partition, cert.Name)
-	log.Printf("certName: %+v\n", certName)
+	b.log().Debug("updating uploaded certificate", "certificate", certName, "source_path", sourcepath)
 	err = b.ModifyCertificate(certName, cert)
 	if err != nil {
 		return err
@@ -530,7 +538,7 @@ func (b *BigIP) UploadKey(keyname, keypath string) (string, error) {
 		return "", err
 	}
 	sourcepath := "file://" + REST_DOWNLOAD_PATH + "/" + keyname
-	log.Println("[DEBUG] string:", sourcepath)
+	b.log().Debug("key uploaded", "key", keyname, "source_path", sourcepath)
 	return sourcepath, nil
 }
 
@@ -542,14 +550,13 @@ func (b *BigIP) UpdateKey(keyname, keypath, partition string) error {
 		return err
 	}
 	sourcepath := "file://" + REST_DOWNLOAD_PATH + "/" + keyname
-	log.Println("[DEBUG]string:", sourcepath)
 	certkey := Key{
 		Name:       keyname,
 		SourcePath: sourcepath,
 		Partition:  partition,
 	}
 	keyName := fmt.Sprintf("/%s/%s", partition, keyname)
-	log.Printf("[DEBUG]keyName: %+v\n", keyName)
+	b.log().Debug("updating uploaded key", "key", keyName, "source_path", sourcepath)
 	err = b.ModifyKey(keyName, &certkey)
 	if err != nil {
 		return err
@@ -621,7 +628,7 @@ func (b *BigIP) ImportIfile(ifile *IFile, fileData, opCall string) error {
 		return err
 	}
 	sourcepath := "file://" + REST_DOWNLOAD_PATH + "/" + ifile.Name
-	log.Println("[DEBUG]string:", sourcepath)
+	b.log().Debug("iFile uploaded", "ifile", ifile.Name, "source_path", sourcepath)
 	ifile.SourcePath = sourcepath
 	// fileName := fmt.Sprintf("/%s/%s", ifile.Partition, ifile.Name)
 	// log.Printf("[DEBUG]fileName: %+v\n", fileName)
@@ -750,10 +757,27 @@ func (b *BigIP) RunCommand(config *BigipCommand) (*BigipCommand, error) {
 	if err != nil {
 		return nil, err
 	}
-	json.Unmarshal(resp, &respRef)
+	if err := json.Unmarshal(resp, &respRef); err != nil {
+		return nil, err
+	}
 	return &respRef, nil
 }
 
+// runBash runs script with the bash utility and returns its output. script
+// is passed to bash -c between double quotes, so it must not contain any.
+func (b *BigIP) runBash(ctx context.Context, script string) (string, error) {
+	return b.runUtil(ctx, uriBash, fmt.Sprintf("-c \"%s\"", script))
+}
+
+// checkShellPath rejects the paths that cannot be single-quoted safely in a
+// runBash script.
+func checkShellPath(p string) error {
+	if strings.ContainsAny(p, "'\"\\$`") {
+		return fmt.Errorf("unsupported characters in path %q", p)
+	}
+	return nil
+}
+
 func (b *BigIP) CreateDNS(description string, nameservers []string, numberofdots int, search []string) error {
 	config := &DNS{
 		Description:  description,
@@ -814,7 +838,7 @@ func (b *BigIP) CreateProvision(name string, fullPath string, cpuRatio int, disk
 }
 
 func (b *BigIP) ProvisionModule(config *Provision) error {
-	log.Printf(" Module Provision:%v", config)
+	b.log().Debug("provisioning module", "module", config.Name, "level", config.Level)
 	if config.Name == "asm" {
 		return b.put(config, uriSys, uriProvision, uriAsm)
 	}
@@ -888,7 +912,6 @@ func (b *BigIP) Provisions(name string) (*Provision, error) {
 
 	}
 
-	log.Println("Display ****************** provision  ", provision)
 	return &provision, nil
 }
 
@@ -956,8 +979,14 @@ func (b *BigIP) CreateTRAP(name string, authPasswordEncrypted string, authProtoc
 	return b.post(config, uriSys, uriSnmp, uriTraps)
 }
 
+// StartTransaction starts a transaction that every request made with b, and
+// with the clients derived from it, joins until CommitTransaction is called,
+// from any goroutine. It sets b.Transaction to the ID of the transaction.
+//
+// Deprecated: use BeginTransaction or WithTransaction, which confine the
+// transaction to a dedicated client.
 func (b *BigIP) StartTransaction() (*Transaction, error) {
-	b.Transaction = ""
+	b.setTransactionID("")
 	body := make(map[string]interface{})
 	resp, err := b.postReq(body, uriMgmt, uriTm, uriTransaction)
 
@@ -969,17 +998,21 @@ func (b *BigIP) StartTransaction() (*Transaction, error) {
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("[INFO] Transaction: %v", transaction)
-	b.Transaction = fmt.Sprint(transaction.TransID)
+	b.log().Debug("transaction started", "transaction_id", transaction.TransID)
+	b.setTransactionID(fmt.Sprint(transaction.TransID))
 	return transaction, nil
 }
 
+// CommitTransaction submits the transaction started by StartTransaction
+// without waiting for its outcome.
+//
+// Deprecated: use Tx.Commit, which waits for the transaction to complete.
 func (b *BigIP) CommitTransaction(tId int64) error {
-	b.Transaction = ""
+	b.setTransactionID("")
 	commitTransaction := map[string]interface{}{
 		"state": "VALIDATING",
 	}
-	log.Printf("[INFO] Commiting Transaction with TransactionID: %v", tId)
+	b.log().Debug("committing transaction", "transaction_id", tId)
 
 	err := b.patch(commitTransaction, uriMgmt, uriTm, uriTransaction, strconv.Itoa(int(tId)))
 	if err != nil {
@@ -1023,11 +1056,13 @@ func (b *BigIP) GetBigipLiceseStatus() (map[string]interface{}, error) {
 	err, _ := b.getForEntityNew(&bigipLicense, uriMgmt, uriTm, uriSys, uriLicense)
 	c := 0
 	for err != nil {
-		time.Sleep(10 * time.Second)
+		if ctxErr := sleepContext(b.context(), 10*time.Second); ctxErr != nil {
+			return nil, ctxErr
+		}
 		c++
 		err, _ = b.getForEntityNew(&bigipLicense, uriMgmt, uriTm, uriSys, uriLicense)
 		if c == 15 {
-			log.Printf("[DEBUG] Device is not up even after waiting for 120 seconds")
+			b.log().Warn("device license still unavailable after waiting", "attempts", c, "error", err)
 			return nil, err
 		}
 	}
@@ -1111,14 +1146,13 @@ func (b *BigIP) UploadDatagroup(tmplpath *os.File, dgname, partition, dgtype str
 		return err
 	}
 	sourcepath := "file://" + REST_DOWNLOAD_PATH + "/" + dgname
-	log.Printf("[DEBUG] sourcepath :%+v", sourcepath)
 	dataGroup := ExternalDGFile{
 		Name:       dgname,
 		SourcePath: sourcepath,
 		Partition:  partition,
 		Type:       dgtype,
 	}
-	log.Printf("External DG: %+v\n", dataGroup)
+	b.log().Debug("external data group file uploaded", "data_group", dgname, "partition", partition, "source_path", sourcepath)
 	if createDg {
 		err = b.AddExternalDatagroupfile(&dataGroup)
 		if err != nil {
@@ -1156,7 +1190,6 @@ func (b *BigIP) UploadDataGroupFile(f *os.File, tmpName string) (*Upload, error)
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("tmpName:%+v", tmpName)
 	return b.Upload(f, info.Size(), uriShared, uriFileTransfer, uriUploads, tmpName)
 }
 
@@ -0,0 +1,421 @@
+package bigip
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SysTestSuite struct {
+	suite.Suite
+	Client       *BigIP
+	Server       *httptest.Server
+	Requests     []string
+	ResponseFunc func(http.ResponseWriter, *http.Request)
+	mu           sync.Mutex
+}
+
+func (s *SysTestSuite) SetupSuite() {
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		s.mu.Lock()
+		s.Requests = append(s.Requests, strings.TrimSpace(r.Method+" "+r.URL.RequestURI()+" "+string(body)))
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if s.ResponseFunc != nil {
+			s.ResponseFunc(w, r)
+		}
+	}))
+	s.Client = NewSession(&Config{
+		Address:           s.Server.URL,
+		CertVerifyDisable: true,
+		ConfigOptions: &ConfigOptions{
+			APICallTimeout: 5 * time.Second,
+			RetryPolicy:    &ExponentialBackoff{MaxAttempts: 2, BaseDelay: time.Millisecond},
+		},
+	})
+	shortenPollInterval(s.T(), &readyPollInterval)
+	shortenPollInterval(s.T(), &softwarePollInterval)
+	shortenPollInterval(s.T(), &statusPollInterval)
+}
+
+func (s *SysTestSuite) TearDownSuite() {
+	s.Server.Close()
+}
+
+func (s *SysTestSuite) SetupTest() {
+	s.ResponseFunc = nil
+	s.Requests = nil
+}
+
+func TestSysSuite(t *testing.T) {
+	suite.Run(t, new(SysTestSuite))
+}
+
+func (s *SysTestSuite) TestUCS() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.Write([]byte(`{"items":[{"kind":"tm:sys:ucs:ucsstate","apiRawValues":{"filename":"/var/local/ucs/pre-change.ucs","file_size":"1234 (in bytes)","file_created_date":"2024-05-01T10:00:00Z","version":"17.1.0","hostname":"bigip1.example.com","encrypted":"yes"}}]}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}
+
+	assert.Nil(s.T(), s.Client.CreateUCS("pre-change", "secret", false))
+	files, err := s.Client.ListUCS()
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), []UCSFile{{
+		Name:      "pre-change.ucs",
+		Path:      "/var/local/ucs/pre-change.ucs",
+		Size:      1234,
+		Created:   "2024-05-01T10:00:00Z",
+		Version:   "17.1.0",
+		Hostname:  "bigip1.example.com",
+		Encrypted: true,
+	}}, files)
+	assert.Nil(s.T(), s.Client.DeleteUCS("pre-change.ucs"))
+	assert.Equal(s.T(), []string{
+		`POST /mgmt/tm/sys/ucs {"command":"save","name":"pre-change.ucs","options":[{"passphrase":"secret"},{"no-private-key":""}]}`,
+		"GET /mgmt/tm/sys/ucs",
+		"DELETE /mgmt/tm/sys/ucs/pre-change.ucs",
+	}, s.Requests)
+}
+
+func (s *SysTestSuite) TestRestoreUCS() {
+	var polls int
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mgmt/tm/sys/ucs":
+			// The device restarts its services during the load.
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		case "/mgmt/tm/sys/ready":
+			// The device answers as ready until the load takes effect.
+			polls++
+			ready := "yes"
+			if polls == 2 {
+				ready = "no"
+			}
+			fmt.Fprintf(w, `{"entries":{"https://localhost/mgmt/tm/sys/ready/0":{"nestedStats":{"entries":{"configReady":{"description":"%s"},"licenseReady":{"description":"yes"},"provisionReady":{"description":"yes"}}}}}}`, ready)
+		case "/mgmt/tm/cm/failover-status":
+			w.Write([]byte(`{"entries":{"https://localhost/mgmt/tm/cm/failover-status/0":{"nestedStats":{"entries":{"color":{"description":"green"},"status":{"description":"ACTIVE"},"summary":{"description":"1/1 active"}}}}}}`))
+		}
+	}
+
+	err := s.Client.RestoreUCS("pre-change", &UCSRestoreOptions{NoLicense: true, ResetTrust: true})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), []string{
+		`POST /mgmt/tm/sys/ucs {"command":"load","name":"pre-change.ucs","options":[{"no-license":""},{"reset-trust":""}]}`,
+		"GET /mgmt/tm/sys/ready",
+		"GET /mgmt/tm/sys/ready",
+		"GET /mgmt/tm/sys/ready",
+		"GET /mgmt/tm/cm/failover-status",
+	}, s.Requests)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = s.Client.WaitForReady(ctx, "STANDBY")
+	assert.True(s.T(), errors.Is(err, context.Canceled), "unexpected error: %v", err)
+}
+
+func (s *SysTestSuite) TestSoftwareUpgrade() {
+	iso := []byte("BIG-IP 17.1.0 image")
+	sum := md5.Sum(iso)
+	var uploaded []byte
+	var volumePolls, versionPolls int
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mgmt/cm/autodeploy/software-image-uploads/BIGIP-17.1.0.iso":
+			body, _ := io.ReadAll(r.Body)
+			uploaded = append(uploaded, body...)
+			w.Write([]byte(`{"remainingByteCount":0}`))
+		case "/mgmt/tm/util/bash":
+			body, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(body), "md5sum") {
+				fmt.Fprintf(w, `{"command":"run","commandResult":"%s  /shared/images/BIGIP-17.1.0.iso\n"}`, hex.EncodeToString(sum[:]))
+				return
+			}
+			w.Write([]byte(`{"command":"run"}`))
+		case "/mgmt/tm/sys/software/image/BIGIP-17.1.0.iso":
+			w.Write([]byte(`{"name":"BIGIP-17.1.0.iso","version":"17.1.0","build":"0.0.16","verified":"yes"}`))
+		case "/mgmt/tm/sys/software/volume/HD1.2":
+			volumePolls++
+			switch volumePolls {
+			case 1:
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"code":404,"message":"01020036:3: The requested Volume (HD1.2) was not found."}`))
+			case 2:
+				w.Write([]byte(`{"name":"HD1.2","status":"installing 42.000 pct"}`))
+			default:
+				w.Write([]byte(`{"name":"HD1.2","status":"complete","version":"17.1.0"}`))
+			}
+		case "/mgmt/tm/sys/version":
+			versionPolls++
+			version, build := "16.1.3", "0.0.12"
+			switch versionPolls {
+			case 1:
+			case 2, 3:
+				// The device goes down for the reboot.
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+				return
+			case 4:
+				// A hotfix of the version is not the version.
+				version, build = "17.1.0.2", "0.0.16"
+			default:
+				version, build = "17.1.0", "0.0.16"
+			}
+			fmt.Fprintf(w, `{"entries":{"https://localhost/mgmt/tm/sys/version/0":{"nestedStats":{"entries":{"Build":{"description":"%s"},"Product":{"description":"BIG-IP"},"Version":{"description":"%s"}}}}}}`, build, version)
+		case "/mgmt/tm/sys/ready":
+			w.Write([]byte(`{"entries":{"https://localhost/mgmt/tm/sys/ready/0":{"nestedStats":{"entries":{"configReady":{"description":"yes"},"licenseReady":{"description":"yes"},"provisionReady":{"description":"yes"}}}}}}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}
+
+	path := s.T().TempDir() + "/BIGIP-17.1.0.iso"
+	assert.Nil(s.T(), os.WriteFile(path, iso, 0600))
+	f, err := os.Open(path)
+	assert.Nil(s.T(), err)
+	defer f.Close()
+
+	ctx := context.Background()
+	image, err := s.Client.UploadSoftwareImage(ctx, f, nil)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "17.1.0", image.Version)
+	assert.Equal(s.T(), iso, uploaded)
+	assert.Nil(s.T(), s.Client.InstallSoftwareImage(image.Name, "HD1.2"))
+	volume, err := s.Client.WaitForSoftwareInstall(ctx, "HD1.2")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "17.1.0", volume.Version)
+	assert.Nil(s.T(), s.Client.SetBootLocation("HD1.2"))
+	assert.Nil(s.T(), s.Client.Reboot(""))
+	assert.Nil(s.T(), s.Client.WaitForVersion(ctx, "17.1.0", "0.0.16"))
+
+	assert.Equal(s.T(), []string{
+		"POST /mgmt/cm/autodeploy/software-image-uploads/BIGIP-17.1.0.iso BIG-IP 17.1.0 image",
+		`POST /mgmt/tm/util/bash {"command":"run","utilCmdArgs":"-c \"md5sum '/shared/images/BIGIP-17.1.0.iso'\""}`,
+		"GET /mgmt/tm/sys/software/image/BIGIP-17.1.0.iso",
+		"GET /mgmt/tm/sys/software/volume/HD1.2",
+		`POST /mgmt/tm/sys/software/image {"command":"install","name":"BIGIP-17.1.0.iso","volume":"HD1.2","options":[{"create-volume":""}]}`,
+		"GET /mgmt/tm/sys/software/volume/HD1.2",
+		"GET /mgmt/tm/sys/software/volume/HD1.2",
+		`POST /mgmt/tm/util/bash {"command":"run","utilCmdArgs":"-c \"switchboot -b HD1.2\""}`,
+		`POST /mgmt/tm/sys {"command":"reboot"}`,
+		"GET /mgmt/tm/sys/version",
+		"GET /mgmt/tm/sys/version",
+		"GET /mgmt/tm/sys/version",
+		"GET /mgmt/tm/sys/version",
+		"GET /mgmt/tm/sys/version",
+		"GET /mgmt/tm/sys/ready",
+	}, s.Requests)
+
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"HD1.2","status":"failed (Unable to find product image)"}`))
+	}
+	_, err = s.Client.WaitForSoftwareInstall(ctx, "HD1.2")
+	assert.Equal(s.T(), "installation to HD1.2 failed: failed (Unable to find product image)", err.Error())
+}
+
+func (s *SysTestSuite) TestSysConfig() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}
+
+	assert.Nil(s.T(), s.Client.SaveSysConfig(nil))
+	assert.Nil(s.T(), s.Client.SaveSysConfig(&SysConfigOptions{Partitions: []string{"Common", "Prod"}}))
+	assert.Nil(s.T(), s.Client.MergeSysConfig(&SysConfigOptions{File: "/var/config/rest/downloads/app.scf", Verify: true}))
+	assert.NotNil(s.T(), s.Client.MergeSysConfig(nil))
+	assert.Equal(s.T(), []string{
+		`POST /mgmt/tm/sys/config {"command":"save"}`,
+		`POST /mgmt/tm/sys/config {"command":"save","options":[{"partitions":"{ Common Prod }"}]}`,
+		`POST /mgmt/tm/sys/config {"command":"load","name":"merge","options":[{"file":"/var/config/rest/downloads/app.scf"},{"verify":""}]}`,
+	}, s.Requests)
+}
+
+func (s *SysTestSuite) TestUtilCommands() {
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		var cmd BigipCommand
+		json.NewDecoder(r.Body).Decode(&cmd)
+		var out string
+		switch r.URL.Path {
+		case "/mgmt/tm/cli/script":
+			out = "done"
+		case "/mgmt/tm/util/unix-ls":
+			out = "app.tar.gz\nmy file.txt\n"
+			if cmd.UtilCmdArgs == "/missing" {
+				out = "/bin/ls: cannot access /missing: No such file or directory\n"
+			}
+		case "/mgmt/tm/util/dig":
+			out = "93.184.216.34\n"
+			if strings.Contains(cmd.UtilCmdArgs, "@10.0.0.53") {
+				out = "\n;; connection timed out; no servers could be reached\n"
+			}
+		case "/mgmt/tm/util/bash":
+			// qkview reports some of its progress as errors.
+			out = "Gathering System Diagnostics: error reading optional file\nexit-status=0\n"
+			if strings.Contains(cmd.UtilCmdArgs, "full.qkview") {
+				out = "qkview: No space left on device\nexit-status=1\n"
+			}
+		case "/mgmt/tm/util/ping":
+			out = "PING 10.0.0.1 (10.0.0.1) 56(84) bytes of data.\n64 bytes from 10.0.0.1: icmp_seq=1 ttl=64 time=0.412 ms\n\n--- 10.0.0.1 ping statistics ---\n2 packets transmitted, 1 received, 50% packet loss, time 1001ms\nrtt min/avg/max/mdev = 0.412/0.412/0.412/0.000 ms\n"
+			if strings.Contains(cmd.UtilCmdArgs, "nowhere") {
+				out = "ping: unknown host nowhere\n"
+			}
+		}
+		json.NewEncoder(w).Encode(&BigipCommand{Command: "run", CommandResult: out})
+	}
+
+	out, err := s.Client.RunCliScript("/Common/rotate", "a", "b")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "done", out)
+	assert.Equal(s.T(), `POST /mgmt/tm/cli/script {"command":"run","utilCmdArgs":"a b","name":"/Common/rotate"}`, s.Requests[0])
+
+	entries, err := s.Client.UnixLs("/var/config/rest/downloads")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), []string{"app.tar.gz", "my file.txt"}, entries)
+	_, err = s.Client.UnixLs("/missing")
+	assert.NotNil(s.T(), err)
+
+	records, err := s.Client.Dig("example.com", "A", "")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), []string{"93.184.216.34"}, records)
+	_, err = s.Client.Dig("example.com", "A", "10.0.0.53")
+	assert.Equal(s.T(), "dig example.com: connection timed out; no servers could be reached", err.Error())
+
+	path, err := s.Client.Qkview("case.qkview")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "/var/tmp/case.qkview", path)
+	assert.Equal(s.T(), `POST /mgmt/tm/util/bash {"command":"run","utilCmdArgs":"-c \"qkview -f '/var/tmp/case.qkview' 2>&1 | tail -n 5; echo exit-status=${PIPESTATUS[0]}\""}`, s.Requests[len(s.Requests)-1])
+	_, err = s.Client.Qkview("full.qkview")
+	assert.Equal(s.T(), "qkview exited with status 1: qkview: No space left on device", err.Error())
+
+	ping, err := s.Client.Ping("10.0.0.1", 2)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, ping.Transmitted)
+	assert.Equal(s.T(), 1, ping.Received)
+	assert.Equal(s.T(), 50.0, ping.PacketLoss)
+	assert.Equal(s.T(), 0.412, ping.AvgRTT)
+	_, err = s.Client.Ping("nowhere", 1)
+	assert.Equal(s.T(), "ping nowhere: ping: unknown host nowhere", err.Error())
+}
+
+func syncStatus(status, color, groupStatus string) string {
+	return fmt.Sprintf(`{"entries":{"https://localhost/mgmt/tm/cm/sync-status/0":{"nestedStats":{"entries":{
+		"color":{"description":"%s"},
+		"mode":{"description":"high-availability"},
+		"status":{"description":"%s"},
+		"summary":{"description":"All devices in the device group are in sync"},
+		"https://localhost/mgmt/tm/cm/syncStatus/0/details":{"nestedStats":{"entries":{
+			"https://localhost/mgmt/tm/cm/syncStatus/0/details/10":{"nestedStats":{"entries":{"details":{"description":"failover-group (%s): All devices in the device group are in sync"}}}},
+			"https://localhost/mgmt/tm/cm/syncStatus/0/details/2":{"nestedStats":{"entries":{"details":{"description":"bigip2.example.com: connected (for 3600 seconds)"}}}}}}}}}}}}`, color, status, groupStatus)
+}
+
+func (s *SysTestSuite) TestConfigSync() {
+	var polls int
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mgmt/tm/cm/sync-status":
+			polls++
+			status, color := "Changes Pending", "yellow"
+			if polls > 1 {
+				status, color = "In Sync", "green"
+			}
+			w.Write([]byte(syncStatus(status, color, status)))
+		case "/mgmt/tm/cm/device-group/failover-group":
+			w.Write([]byte(`{"name":"failover-group","partition":"Common","type":"sync-failover"}`))
+		case "/mgmt/tm/cm/device-group/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"01020036:3: The requested device group (/Common/missing) was not found."}`))
+		case "/mgmt/tm/util/bash":
+			w.Write([]byte(`{"command":"run"}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}
+
+	assert.Nil(s.T(), s.Client.SaveConfig())
+	assert.Nil(s.T(), s.Client.ConfigSync("failover-group", SyncToGroup))
+	assert.NotNil(s.T(), s.Client.ConfigSync("failover-group", "sideways"))
+	assert.True(s.T(), IsNotFound(s.Client.ConfigSync("missing", SyncToGroup)))
+	status, err := s.Client.WaitForSync(context.Background())
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), &SyncStatus{
+		Status:  "In Sync",
+		Color:   "green",
+		Mode:    "high-availability",
+		Summary: "All devices in the device group are in sync",
+		Details: []string{
+			"bigip2.example.com: connected (for 3600 seconds)",
+			"failover-group (In Sync): All devices in the device group are in sync",
+		},
+	}, status)
+	assert.Equal(s.T(), []string{
+		`POST /mgmt/tm/sys/config {"command":"save"}`,
+		"GET /mgmt/tm/cm/device-group/failover-group",
+		`POST /mgmt/tm/util/bash {"command":"run","utilCmdArgs":"-c \"tmsh run cm config-sync to-group failover-group\""}`,
+		"GET /mgmt/tm/cm/device-group/missing",
+		"GET /mgmt/tm/cm/sync-status",
+		"GET /mgmt/tm/cm/sync-status",
+	}, s.Requests)
+}
+
+func (s *SysTestSuite) TestWaitForDeviceGroupSync() {
+	var polls int
+	s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mgmt/tm/cm/sync-status":
+			// The device is out of sync in another group only.
+			polls++
+			group := "Changes Pending"
+			if polls > 1 {
+				group = "In Sync"
+			}
+			w.Write([]byte(syncStatus("Changes Pending", "yellow", group)))
+		case "/mgmt/tm/cm/device-group/failover-group":
+			w.Write([]byte(`{"name":"failover-group","partition":"Common","type":"sync-failover"}`))
+		case "/mgmt/tm/cm/device-group/failover-group/devices":
+			w.Write([]byte(`{"items":[{"name":"bigip1.example.com"},{"name":"bigip2.example.com"}]}`))
+		case "/mgmt/tm/cm/device":
+			w.Write([]byte(`{"items":[{"name":"bigip1.example.com","failoverState":"active"},{"name":"bigip2.example.com","failoverState":"standby"},{"name":"bigip3.example.com"}]}`))
+		}
+	}
+
+	status, err := s.Client.WaitForSync(context.Background(), "failover-group")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "Changes Pending", status.Status)
+
+	group, err := s.Client.DeviceGroupSyncStatus("failover-group")
+	assert.Nil(s.T(), err)
+	assert.True(s.T(), group.InSync())
+	assert.Equal(s.T(), "sync-failover", group.Devicegroup.Type)
+	assert.Equal(s.T(), 2, len(group.Devices))
+	assert.Equal(s.T(), "standby", group.Devices[1].FailoverState)
+
+	for _, failure := range []string{"Sync Failure", "Disconnected", "Standalone"} {
+		polls = 0
+		s.ResponseFunc = func(w http.ResponseWriter, r *http.Request) {
+			polls++
+			w.Write([]byte(syncStatus(failure, "red", failure)))
+		}
+		_, err := s.Client.WaitForSync(context.Background())
+		assert.NotNil(s.T(), err, failure)
+		assert.Equal(s.T(), 1, polls, failure)
+	}
+}
@@ -0,0 +1,129 @@
+package bigip
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// newTLSConfig builds the TLS configuration described by the Config fields
+// TrustedCertificate, TrustedCertificatePEM, ClientCertificate, ClientKey,
+// CertificateFingerprint, MinTLSVersion and CertVerifyDisable.
+func newTLSConfig(c *Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.CertVerifyDisable,
+		MinVersion:         c.MinTLSVersion,
+	}
+
+	var rootCAs *x509.CertPool
+	if c.TrustedCertificate != "" || len(c.TrustedCertificatePEM) > 0 {
+		rootCAs, _ = x509.SystemCertPool()
+		if rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		appended := rootCAs.AppendCertsFromPEM(c.TrustedCertificatePEM)
+		if c.TrustedCertificate != "" {
+			certPEM, err := os.ReadFile(c.TrustedCertificate)
+			if err != nil {
+				return nil, fmt.Errorf("provide Valid Trusted certificate path :%+v", err)
+			}
+			appended = rootCAs.AppendCertsFromPEM(certPEM) || appended
+		}
+		if !appended {
+			return nil, errors.New("no certificates found in the trusted certificate PEM")
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if c.ClientCertificate != "" || len(c.ClientCertificatePEM) > 0 {
+		cert, err := loadClientCertificate(c)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if c.CertificateFingerprint != "" {
+		pin, err := parseFingerprint(c.CertificateFingerprint)
+		if err != nil {
+			return nil, err
+		}
+		// The pin is checked on every connection. Chain verification is
+		// done by hand afterwards, unless disabled, so that self-signed
+		// device certificates can be trusted through the pin alone.
+		verifyChain := !c.CertVerifyDisable && rootCAs != nil
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyPinnedConnection(cs, pin, verifyChain, rootCAs)
+		}
+	}
+	return tlsConfig, nil
+}
+
+func loadClientCertificate(c *Config) (tls.Certificate, error) {
+	certPEM, keyPEM := c.ClientCertificatePEM, c.ClientKeyPEM
+	if c.ClientCertificate != "" {
+		data, err := os.ReadFile(c.ClientCertificate)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to read client certificate: %w", err)
+		}
+		certPEM = data
+	}
+	if c.ClientKey != "" {
+		data, err := os.ReadFile(c.ClientKey)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to read client key: %w", err)
+		}
+		keyPEM = data
+	}
+	if len(keyPEM) == 0 {
+		// The key may be bundled in the same PEM as the certificate.
+		keyPEM = certPEM
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("invalid client certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// parseFingerprint decodes a SHA-256 fingerprint written as hex, with or
+// without colons, as printed by "openssl x509 -fingerprint -sha256".
+func parseFingerprint(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "sha256:")
+	pin, err := hex.DecodeString(strings.ReplaceAll(s, ":", ""))
+	if err != nil || len(pin) != sha256.Size {
+		return nil, fmt.Errorf("invalid SHA-256 certificate fingerprint %q", s)
+	}
+	return pin, nil
+}
+
+func verifyPinnedConnection(cs tls.ConnectionState, pin []byte, verifyChain bool, roots *x509.CertPool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("device presented no certificate")
+	}
+	leaf := cs.PeerCertificates[0]
+	sum := sha256.Sum256(leaf.Raw)
+	if !bytes.Equal(sum[:], pin) {
+		return fmt.Errorf("device certificate fingerprint %s does not match the pinned fingerprint", hex.EncodeToString(sum[:]))
+	}
+	if !verifyChain {
+		return nil
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
@@ -0,0 +1,289 @@
+package bigip
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// transactionPollInterval is the delay between two status checks while a
+// committed transaction is validated and executed.
+var transactionPollInterval = time.Second
+
+// isTransactionURL reports whether path manages a transaction itself, and
+// must therefore never be stamped with a coordination ID.
+func isTransactionURL(path string) bool {
+	return strings.HasPrefix(path, uriMgmt+"/"+uriTm+"/"+uriTransaction)
+}
+
+// Tx is an iControl REST transaction. Requests made with the client returned
+// by Client are queued in the transaction instead of being applied, until
+// Commit applies them all at once or Rollback discards them. Other requests
+// made with the parent client are not affected.
+type Tx struct {
+	ID int64
+
+	// b manages the transaction; client is bound to it.
+	b      *BigIP
+	client *BigIP
+}
+
+// TransactionCommand is a request queued in a transaction.
+type TransactionCommand struct {
+	CommandID int64           `json:"commandId,omitempty"`
+	EvalOrder int64           `json:"evalOrder,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	URI       string          `json:"uri,omitempty"`
+	Body      json.RawMessage `json:"body,omitempty"`
+}
+
+// TransactionError is returned by Tx.Commit when the device rejects the
+// transaction. None of its commands has been applied.
+type TransactionError struct {
+	ID int64
+	// Reason is the failure reported by the device.
+	Reason string
+	// Command is the queued command Reason refers to, when it can be told
+	// from the reason.
+	Command *TransactionCommand
+	// Err is the *APIError returned by the device, if the commit request
+	// itself failed.
+	Err error
+}
+
+func (e *TransactionError) Error() string {
+	if e.Command != nil {
+		return fmt.Sprintf("transaction %d failed on %s %s: %s", e.ID, e.Command.Method, e.Command.URI, e.Reason)
+	}
+	return fmt.Sprintf("transaction %d failed: %s", e.ID, e.Reason)
+}
+
+func (e *TransactionError) Unwrap() error {
+	return e.Err
+}
+
+// BeginTransaction starts a new transaction on the device.
+func (b *BigIP) BeginTransaction() (*Tx, error) {
+	resp, err := b.postReq(map[string]interface{}{}, uriMgmt, uriTm, uriTransaction)
+	if err != nil {
+		return nil, fmt.Errorf("error encountered while starting transaction: %w", err)
+	}
+	var transaction Transaction
+	if err := json.Unmarshal(resp, &transaction); err != nil {
+		return nil, err
+	}
+	client := b.WithContext(b.context())
+	client.tx = strconv.FormatInt(transaction.TransID, 10)
+	b.log().Debug("transaction started", "transaction_id", transaction.TransID)
+	return &Tx{ID: transaction.TransID, b: b, client: client}, nil
+}
+
+// WithTransaction runs fn in a new transaction. The transaction is committed
+// when fn returns nil, and rolled back when it returns an error or panics.
+func (b *BigIP) WithTransaction(fn func(tx *Tx) error) error {
+	tx, err := b.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	committing := false
+	defer func() {
+		if committing {
+			return
+		}
+		if rbErr := tx.Rollback(); rbErr != nil {
+			b.log().Warn("failed to roll back transaction", "transaction_id", tx.ID, "error", rbErr)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	committing = true
+	return tx.Commit()
+}
+
+// Client returns a client whose requests are queued in the transaction. It
+// shares the session and the context of the client that began it.
+func (tx *Tx) Client() *BigIP {
+	return tx.client
+}
+
+func (tx *Tx) id() string {
+	return strconv.FormatInt(tx.ID, 10)
+}
+
+// Status returns the current state of the transaction.
+func (tx *Tx) Status() (*Transaction, error) {
+	var transaction Transaction
+	err, _ := tx.b.getForEntity(&transaction, uriMgmt, uriTm, uriTransaction, tx.id())
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// Commands returns the requests queued in the transaction, in evaluation order.
+func (tx *Tx) Commands() ([]TransactionCommand, error) {
+	var commands struct {
+		Items []TransactionCommand `json:"items"`
+	}
+	err, _ := tx.b.getForEntity(&commands, uriMgmt, uriTm, uriTransaction, tx.id(), "commands")
+	if err != nil {
+		return nil, err
+	}
+	return commands.Items, nil
+}
+
+// Commit submits the transaction and waits until the device has either
+// applied every command or rejected the transaction, in which case the
+// error is a *TransactionError.
+func (tx *Tx) Commit() error {
+	tx.b.log().Debug("committing transaction", "transaction_id", tx.ID)
+	resp, err := tx.b.fastPatch(map[string]interface{}{"state": "VALIDATING"}, uriMgmt, uriTm, uriTransaction, tx.id())
+	if err != nil {
+		if apiErr, ok := asAPIError(err); ok {
+			return tx.failure(apiErr.Error(), err)
+		}
+		return err
+	}
+	var status Transaction
+	if err := json.Unmarshal(resp, &status); err != nil {
+		return err
+	}
+	for {
+		switch status.State {
+		case "COMPLETED":
+			return nil
+		case "FAILED":
+			return tx.failure(status.FailureReason, nil)
+		}
+		if err := sleepContext(tx.b.context(), transactionPollInterval); err != nil {
+			return err
+		}
+		s, err := tx.Status()
+		if err != nil {
+			return err
+		}
+		status = *s
+	}
+}
+
+// failure builds the TransactionError for reason, looking up the command it
+// names among the queued ones, and deletes the failed transaction.
+func (tx *Tx) failure(reason string, err error) *TransactionError {
+	txErr := &TransactionError{ID: tx.ID, Reason: reason, Err: err}
+	commands, cmdErr := tx.Commands()
+	if cmdErr == nil {
+		txErr.Command = failedCommand(reason, commands)
+	}
+	if rbErr := tx.Rollback(); rbErr != nil {
+		tx.b.log().Warn("failed to delete transaction", "transaction_id", tx.ID, "error", rbErr)
+	}
+	return txErr
+}
+
+// failedCommand returns the command whose object reason names by its full
+// path, or whose position reason gives, if exactly one command matches.
+func failedCommand(reason string, commands []TransactionCommand) *TransactionCommand {
+	var found *TransactionCommand
+	for i := range commands {
+		path := commandObjectPath(commands[i])
+		if (path != "" && containsPath(reason, path)) || containsCommandIndex(reason, commands[i].EvalOrder) {
+			if found != nil {
+				return nil
+			}
+			found = &commands[i]
+		}
+	}
+	return found
+}
+
+// commandObjectPath returns the full path of the object a command acts on:
+// taken from the body of a POST to a collection, and from the URI otherwise.
+func commandObjectPath(command TransactionCommand) string {
+	var body struct {
+		Name      string `json:"name"`
+		Partition string `json:"partition"`
+		FullPath  string `json:"fullPath"`
+	}
+	json.Unmarshal(command.Body, &body)
+	switch {
+	case body.FullPath != "":
+		return body.FullPath
+	case body.Name != "" && strings.EqualFold(command.Method, "POST"):
+		if strings.HasPrefix(body.Name, "/") {
+			return body.Name
+		}
+		partition := body.Partition
+		if partition == "" {
+			partition = "Common"
+		}
+		return "/" + partition + "/" + body.Name
+	}
+	uri := command.URI
+	if q := strings.IndexByte(uri, '?'); q >= 0 {
+		uri = uri[:q]
+	}
+	name := uri[strings.LastIndexByte(uri, '/')+1:]
+	if !strings.HasPrefix(name, "~") {
+		// A name without a partition is too short to be told apart in a
+		// reason.
+		return ""
+	}
+	return strings.ReplaceAll(name, "~", "/")
+}
+
+// containsPath reports whether reason names path, and not a longer path
+// path is a prefix of.
+func containsPath(reason, path string) bool {
+	for i := 0; ; {
+		j := strings.Index(reason[i:], path)
+		if j < 0 {
+			return false
+		}
+		end := i + j + len(path)
+		// A dot ending a sentence does not continue the name.
+		if end < len(reason) && reason[end] == '.' {
+			end++
+		}
+		if end == len(reason) || !isNameChar(reason[end]) {
+			return true
+		}
+		i += j + 1
+	}
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '-' || c == '.' || c == '/' || c == '%' ||
+		'0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// commandIndexPattern matches a reason referring to a command by its
+// position, as in "command 2".
+var commandIndexPattern = regexp.MustCompile(`(?i)\bcommand\s+#?(\d+)\b`)
+
+// containsCommandIndex reports whether reason refers to the command at
+// evalOrder by its position.
+func containsCommandIndex(reason string, evalOrder int64) bool {
+	if evalOrder == 0 {
+		return false
+	}
+	for _, m := range commandIndexPattern.FindAllStringSubmatch(reason, -1) {
+		if m[1] == strconv.FormatInt(evalOrder, 10) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rollback discards the transaction and every command queued in it. Rolling
+// back a transaction that no longer exists is not an error.
+func (tx *Tx) Rollback() error {
+	err := tx.b.delete(uriMgmt, uriTm, uriTransaction, tx.id())
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
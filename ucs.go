@@ -0,0 +1,255 @@
+package bigip
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	uriUcs            = "ucs"
+	uriUcsDownloads   = "ucs-downloads"
+	uriUcsUploads     = "ucs-uploads"
+	uriReady          = "ready"
+	uriFailoverStatus = "failover-status"
+	ucsDirectory      = "/var/local/ucs"
+)
+
+// readyPollInterval is the delay between two readiness checks while the
+// device restarts its services.
+var readyPollInterval = 10 * time.Second
+
+// UCSFile is a UCS archive stored on the device, in /var/local/ucs.
+type UCSFile struct {
+	Name      string
+	Path      string
+	Size      int64
+	Created   string
+	Version   string
+	Hostname  string
+	Encrypted bool
+}
+
+type ucsDTO struct {
+	APIRawValues struct {
+		Filename        string `json:"filename"`
+		FileSize        string `json:"file_size"`
+		FileCreatedDate string `json:"file_created_date"`
+		Version         string `json:"version"`
+		Hostname        string `json:"hostname"`
+		Encrypted       string `json:"encrypted"`
+	} `json:"apiRawValues"`
+}
+
+// UCSRestoreOptions tunes RestoreUCS.
+type UCSRestoreOptions struct {
+	// Passphrase decrypts an encrypted archive.
+	Passphrase string
+	// NoLicense keeps the license of the device instead of restoring the
+	// one in the archive, as when restoring to another device.
+	NoLicense bool
+	// NoPlatformCheck allows restoring an archive taken on another platform.
+	NoPlatformCheck bool
+	// ResetTrust resets the device trust, as when restoring to another device.
+	ResetTrust bool
+	// FailoverStatus is the failover status the device must reach to be
+	// considered back, "ACTIVE" by default. Use "STANDBY" for the standby
+	// unit of a pair.
+	FailoverStatus string
+	// Timeout bounds the restore, including the wait for the device to be
+	// ready again. Defaults to twenty minutes.
+	Timeout time.Duration
+}
+
+// ucsName adds the .ucs extension to name when it is missing, as tmsh does.
+func ucsName(name string) string {
+	if strings.HasSuffix(name, ".ucs") {
+		return name
+	}
+	return name + ".ucs"
+}
+
+// ucsCommand is the body of the save and load commands of sys/ucs. Every
+// option is an object of its own; flags have an empty value.
+type ucsCommand struct {
+	Command string              `json:"command"`
+	Name    string              `json:"name"`
+	Options []map[string]string `json:"options,omitempty"`
+}
+
+// CreateUCS saves the configuration of the device to the UCS archive name in
+// /var/local/ucs. The archive is encrypted when passphrase is set, and holds
+// the private keys of the device only if includePrivateKeys is true. Saving
+// a large configuration may take longer than the default APICallTimeout.
+func (b *BigIP) CreateUCS(name, passphrase string, includePrivateKeys bool) error {
+	cmd := &ucsCommand{Command: "save", Name: ucsName(name)}
+	if passphrase != "" {
+		cmd.Options = append(cmd.Options, map[string]string{"passphrase": passphrase})
+	}
+	if !includePrivateKeys {
+		cmd.Options = append(cmd.Options, map[string]string{"no-private-key": ""})
+	}
+	return b.post(cmd, uriSys, uriUcs)
+}
+
+// ListUCS returns the UCS archives stored on the device.
+func (b *BigIP) ListUCS() ([]UCSFile, error) {
+	var list struct {
+		Items []ucsDTO `json:"items"`
+	}
+	err, _ := b.getForEntity(&list, uriSys, uriUcs)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]UCSFile, 0, len(list.Items))
+	for _, item := range list.Items {
+		raw := item.APIRawValues
+		f := UCSFile{
+			Path:      raw.Filename,
+			Name:      raw.Filename[strings.LastIndexByte(raw.Filename, '/')+1:],
+			Created:   raw.FileCreatedDate,
+			Version:   raw.Version,
+			Hostname:  raw.Hostname,
+			Encrypted: raw.Encrypted == "yes",
+		}
+		// The size is reported as "1234 (in bytes)".
+		fmt.Sscanf(raw.FileSize, "%d", &f.Size)
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// DeleteUCS removes the UCS archive name from the device.
+func (b *BigIP) DeleteUCS(name string) error {
+	return b.delete(uriSys, uriUcs, ucsName(name))
+}
+
+// DownloadUCS copies the UCS archive name to w.
+func (b *BigIP) DownloadUCS(ctx context.Context, name string, w io.Writer) (int64, error) {
+	return b.Download(ctx, b.iControlPath([]string{uriMgmt, uriShared, uriFileTransfer, uriUcsDownloads, ucsName(name)}), w)
+}
+
+// UploadUCS uploads a UCS archive of size bytes read from r to
+// /var/local/ucs, where RestoreUCS can load it.
+func (b *BigIP) UploadUCS(ctx context.Context, r io.Reader, size int64, name string, opts *UploadOptions) (*Upload, error) {
+	return b.UploadWithOptions(ctx, r, size, opts, uriShared, uriFileTransfer, uriUcsUploads, ucsName(name))
+}
+
+// ucsRestartTimeout bounds the wait for the device to begin restarting its
+// services after a UCS load request was interrupted.
+var ucsRestartTimeout = 5 * time.Minute
+
+// RestoreUCS loads the UCS archive name, replacing the configuration of the
+// device, and waits until the device is ready again with the expected
+// failover status. The device restarts its services while loading, so the
+// load request itself may fail with a transport error; the outcome is then
+// told by whether the device comes back.
+func (b *BigIP) RestoreUCS(name string, opts *UCSRestoreOptions) error {
+	return b.RestoreUCSContext(b.context(), name, opts)
+}
+
+// RestoreUCSContext is the context-aware variant of RestoreUCS. The wait for
+// the device stops once ctx is done.
+func (b *BigIP) RestoreUCSContext(ctx context.Context, name string, opts *UCSRestoreOptions) error {
+	o := UCSRestoreOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 20 * time.Minute
+	}
+	if o.FailoverStatus == "" {
+		o.FailoverStatus = "ACTIVE"
+	}
+	ctx, cancel := context.WithTimeout(ctx, o.Timeout)
+	defer cancel()
+
+	cmd := &ucsCommand{Command: "load", Name: ucsName(name)}
+	if o.Passphrase != "" {
+		cmd.Options = append(cmd.Options, map[string]string{"passphrase": o.Passphrase})
+	}
+	flags := []struct {
+		name string
+		set  bool
+	}{{"no-license", o.NoLicense}, {"no-platform-check", o.NoPlatformCheck}, {"reset-trust", o.ResetTrust}}
+	for _, flag := range flags {
+		if flag.set {
+			cmd.Options = append(cmd.Options, map[string]string{flag.name: ""})
+		}
+	}
+	if err := b.postContext(ctx, cmd, uriSys, uriUcs); err != nil {
+		if _, ok := asAPIError(err); ok {
+			return fmt.Errorf("failed to restore UCS %s: %w", name, err)
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		// The device may still answer as ready until the load takes effect.
+		b.log().Warn("UCS load request interrupted, waiting for the device to restart", "ucs", name, "error", err)
+		if err := b.waitForRestart(ctx, ucsRestartTimeout); err != nil {
+			return err
+		}
+	}
+	return b.WaitForReady(ctx, o.FailoverStatus)
+}
+
+// waitForRestart polls the device until it stops answering or reports that
+// it is not ready, which tells that it has begun restarting. If it does not
+// within timeout, the restart is taken to be over already.
+func (b *BigIP) waitForRestart(ctx context.Context, timeout time.Duration) error {
+	restartCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := poll(restartCtx, readyPollInterval, func(ctx context.Context) (bool, error) {
+		ready, err := b.ready(ctx, "")
+		return err != nil || !ready, nil
+	})
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("device not ready: %w", ctx.Err())
+	}
+	if err != nil {
+		b.log().Warn("device did not restart", "timeout", timeout)
+	}
+	return nil
+}
+
+// WaitForReady polls the device until its configuration, license and
+// provisioning are ready and, when failoverStatus is set, its failover
+// status is that one, such as "ACTIVE". Errors are retried until ctx is
+// done, since the device may not answer while its services restart.
+func (b *BigIP) WaitForReady(ctx context.Context, failoverStatus string) error {
+	err := poll(ctx, readyPollInterval, func(ctx context.Context) (bool, error) {
+		return b.ready(ctx, failoverStatus)
+	})
+	if err != nil {
+		return fmt.Errorf("device not ready: %w", err)
+	}
+	return nil
+}
+
+func (b *BigIP) ready(ctx context.Context, failoverStatus string) (bool, error) {
+	client := b.WithContext(ctx)
+	stats, err := client.getObjectStats(uriSys, uriReady)
+	if err != nil {
+		return false, err
+	}
+	for _, key := range []string{"configReady", "licenseReady", "provisionReady"} {
+		if v := stats.Descriptions[key]; v != "yes" {
+			b.log().Debug("device not ready", "check", key, "value", v)
+			return false, nil
+		}
+	}
+	if failoverStatus == "" {
+		return true, nil
+	}
+	stats, err = client.getObjectStats(uriCm, uriFailoverStatus)
+	if err != nil {
+		return false, err
+	}
+	if status := stats.Descriptions["status"]; !strings.EqualFold(status, failoverStatus) {
+		b.log().Debug("device not ready", "failover_status", status)
+		return false, nil
+	}
+	return true, nil
+}
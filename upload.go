@@ -0,0 +1,180 @@
+package bigip
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// defaultUploadChunkSize is the chunk size the device handles best; it
+// rejects chunks larger than 1 MiB.
+const defaultUploadChunkSize = 512 * 1024
+
+// UploadOptions tunes Upload.
+type UploadOptions struct {
+	// ChunkSize is the number of bytes sent at a time. Defaults to 512 KiB.
+	ChunkSize int64
+	// Progress, if set, is called after every chunk with the number of bytes
+	// uploaded so far and the file size.
+	Progress func(done, total int64)
+	// Verify checks once the upload is complete that the file stored on the
+	// device has the expected size and the SHA-256 of the bytes read.
+	Verify bool
+	// SHA256 is the expected hex digest of the file. When set, the bytes read
+	// are checked against it before the last chunk is sent, and the file
+	// stored on the device is verified as with Verify.
+	SHA256 string
+}
+
+// Upload a file read from a Reader
+func (b *BigIP) Upload(r io.Reader, size int64, path ...string) (*Upload, error) {
+	return b.UploadContext(b.context(), r, size, path...)
+}
+
+// UploadContext uploads a file read from a Reader, stopping between or during
+// chunks once ctx is done.
+func (b *BigIP) UploadContext(ctx context.Context, r io.Reader, size int64, path ...string) (*Upload, error) {
+	return b.UploadWithOptions(ctx, r, size, nil, path...)
+}
+
+// UploadWithOptions uploads size bytes read from r to path, such as
+// "shared", "file-transfer", "uploads", "app.tar.gz", with control over
+// chunking, progress reporting and verification. Each chunk is retried
+// according to the RetryPolicy, transport errors included, since sending a
+// chunk again only rewrites the same bytes.
+func (b *BigIP) UploadWithOptions(ctx context.Context, r io.Reader, size int64, opts *UploadOptions, path ...string) (*Upload, error) {
+	if opts == nil {
+		opts = &UploadOptions{}
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("cannot upload %d bytes", size)
+	}
+	options := &APIRequest{
+		Method:      "post",
+		URL:         b.iControlPath(path),
+		ContentType: "application/octet-stream",
+		idempotent:  true,
+	}
+	var format string
+	if strings.Contains(options.URL, "mgmt/") {
+		format = "%s/%s"
+	} else {
+		format = "%s/mgmt/%s"
+	}
+	urlString := fmt.Sprintf(format, b.Host, options.URL)
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultUploadChunkSize
+	}
+
+	h := sha256.New()
+	buf := make([]byte, min(chunkSize, size))
+	var upload Upload
+	for start := int64(0); start < size; {
+		chunk := buf[:min(chunkSize, size-start)]
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, fmt.Errorf("upload source ended before %d bytes were read: %w", size, io.ErrUnexpectedEOF)
+			}
+			return nil, err
+		}
+		h.Write(chunk)
+		end := start + int64(len(chunk))
+		if end == size && opts.SHA256 != "" {
+			if err := verifySHA256(h, opts.SHA256); err != nil {
+				return nil, err
+			}
+		}
+		header := http.Header{}
+		header.Set("Content-Range", fmt.Sprintf("%d-%d/%d", start, end-1, size))
+		res, err := b.doAuthenticated(ctx, options, urlString, chunk, header)
+		if err != nil {
+			return nil, err
+		}
+		upload = Upload{}
+		if err := json.Unmarshal(res.Body, &upload); err != nil {
+			return nil, err
+		}
+		start = end
+		if opts.Progress != nil {
+			opts.Progress(start, size)
+		}
+	}
+
+	if opts.Verify || opts.SHA256 != "" {
+		remote := upload.LocalFilePath
+		if remote == "" {
+			remote = uploadedFilePath(options.URL)
+		}
+		if remote == "" {
+			return &upload, fmt.Errorf("cannot verify upload to %s: unknown location on the device", options.URL)
+		}
+		if err := b.verifyRemoteFile(ctx, remote, size, hex.EncodeToString(h.Sum(nil))); err != nil {
+			return &upload, err
+		}
+	}
+	return &upload, nil
+}
+
+// uploadedFilePath returns where the device stores a file uploaded to the
+// endpoint at url, for endpoints whose response may not say so.
+func uploadedFilePath(url string) string {
+	dir, name := path.Split(strings.TrimPrefix(url, uriMgmt+"/"))
+	switch strings.TrimSuffix(dir, "/") {
+	case uriShared + "/" + uriFileTransfer + "/" + uriUploads:
+		return REST_DOWNLOAD_PATH + "/" + name
+	case uriShared + "/" + uriFileTransfer + "/" + uriUcsUploads:
+		return ucsDirectory + "/" + name
+	case uriCm + "/" + uriAutodeploy + "/" + uriSoftwareImageUploads:
+		return "/shared/images/" + name
+	case uriTm + "/" + uriAsm + "/" + uriFileTransfer + "/" + uriUploads:
+		return "/var/ts/var/rest/" + name
+	}
+	return ""
+}
+
+// verifyRemoteFile checks the size and SHA-256 of the file at remote on the
+// device, using the bash utility.
+func (b *BigIP) verifyRemoteFile(ctx context.Context, remote string, size int64, sum string) error {
+	if err := checkShellPath(remote); err != nil {
+		return err
+	}
+	out, err := b.runBash(ctx, fmt.Sprintf("stat -c %%s '%s' && sha256sum '%s'", remote, remote))
+	if err != nil {
+		return fmt.Errorf("failed to verify %s: %w", remote, err)
+	}
+	fields := strings.Fields(out)
+	if len(fields) < 2 {
+		return fmt.Errorf("failed to verify %s: %s", remote, strings.TrimSpace(out))
+	}
+	if n, err := strconv.ParseInt(fields[0], 10, 64); err != nil || n != size {
+		return fmt.Errorf("uploaded file %s has %s bytes, want %d", remote, fields[0], size)
+	}
+	if !strings.EqualFold(fields[1], sum) {
+		return fmt.Errorf("%w: %s on the device has SHA-256 %s, want %s", ErrChecksumMismatch, remote, fields[1], sum)
+	}
+	return nil
+}
+
+// UploadFileWithOptions uploads f, sending software images (.iso) to the
+// software image store and any other file to the file transfer directory,
+// /var/config/rest/downloads.
+func (b *BigIP) UploadFileWithOptions(ctx context.Context, f *os.File, opts *UploadOptions) (*Upload, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(info.Name(), ".iso") {
+		return b.UploadWithOptions(ctx, f, info.Size(), opts, uriCm, uriAutodeploy, uriSoftwareImageUploads, info.Name())
+	}
+	return b.UploadWithOptions(ctx, f, info.Size(), opts, uriShared, uriFileTransfer, uriUploads, info.Name())
+}